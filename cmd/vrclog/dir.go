@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	vrclog "github.com/vrclog/vrclog-go"
+)
+
+func cmdDir(args []string) {
+	os.Exit(runDir(args, os.Stdout, os.Stderr))
+}
+
+// runDir prints the log directory the default-directory callers use. With
+// --explain it also lists every rule tried, and with --json it prints the
+// whole resolution. If resolution fails, the error goes to stderr, and
+// without --json so do the rules tried.
+func runDir(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("dir", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	explain := fs.Bool("explain", false, "list every rule tried and why it was rejected")
+	asJSON := fs.Bool("json", false, "print the resolution as JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	res, err := vrclog.ResolveLogDirectory()
+	if *asJSON {
+		if encErr := json.NewEncoder(stdout).Encode(res); encErr != nil {
+			fmt.Fprintf(stderr, "vrclog: %v\n", encErr)
+			return 1
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "vrclog: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "vrclog: %v\n", err)
+		printCandidates(stderr, res.Candidates)
+		return 1
+	}
+	fmt.Fprintln(stdout, res.Dir)
+	if *explain {
+		printCandidates(stdout, res.Candidates)
+	}
+	return 0
+}
+
+// printCandidates writes one line per rule tried.
+func printCandidates(w io.Writer, candidates []vrclog.LogDirectoryCandidate) {
+	for _, c := range candidates {
+		where := c.Origin
+		if c.Dir != "" {
+			if where != "" {
+				where += " "
+			}
+			where += c.Dir
+		}
+		if where != "" {
+			where += ": "
+		}
+		if c.Rejected != "" {
+			fmt.Fprintf(w, "  %-9s %srejected: %s\n", c.Rule, where, c.Rejected)
+		} else {
+			fmt.Fprintf(w, "  %-9s %sselected\n", c.Rule, where)
+		}
+	}
+}
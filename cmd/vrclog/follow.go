@@ -8,10 +8,17 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	vrclog "github.com/vrclog/vrclog-go"
 )
 
+var truncationPolicies = map[string]vrclog.TruncationPolicy{
+	"fail":   vrclog.TruncationFail,
+	"rewind": vrclog.TruncationRewind,
+	"skip":   vrclog.TruncationSkipToNext,
+}
+
 func cmdFollow(args []string) {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
@@ -22,10 +29,30 @@ func runFollow(ctx context.Context, args []string, stdout, stderr io.Writer) int
 	fs := flag.NewFlagSet("follow", flag.ContinueOnError)
 	fs.SetOutput(stderr)
 	dir := fs.String("dir", "", "log directory path")
+	cursorFile := fs.String("cursor-file", "", "file to resume from and checkpoint the follow position to")
+	tz := fs.String("tz", "", "IANA time zone the logs were written in (default: local)")
+	header := fs.String("header", "vrchat", "log header format: vrchat, ms, beta or unity")
+	truncation := fs.String("truncation", "fail", "what to do when the followed file is truncated: fail, rewind or skip")
 	if err := fs.Parse(args); err != nil {
 		return 2
 	}
 
+	loc, err := loadLocation(*tz)
+	if err != nil {
+		fmt.Fprintf(stderr, "vrclog: %v\n", err)
+		return 2
+	}
+	headers, err := loadHeaderDecoder(*header)
+	if err != nil {
+		fmt.Fprintf(stderr, "vrclog: %v\n", err)
+		return 2
+	}
+	policy, ok := truncationPolicies[*truncation]
+	if !ok {
+		fmt.Fprintf(stderr, "vrclog: unknown truncation policy %q (want fail, rewind or skip)\n", *truncation)
+		return 2
+	}
+
 	logDir := *dir
 	if logDir == "" {
 		d, err := vrclog.DefaultLogDirectory()
@@ -42,8 +69,31 @@ func runFollow(ctx context.Context, args []string, stdout, stderr io.Writer) int
 		return 1
 	}
 
+	cfg := vrclog.FollowConfig{
+		Directory:  logDir,
+		Location:   loc,
+		Header:     headers,
+		Truncation: policy,
+		OnSource: func(n vrclog.SourceNotification) {
+			if n.Kind != vrclog.SourceTruncated {
+				return
+			}
+			tr := n.Truncation
+			fmt.Fprintf(stderr, "vrclog: %s truncated from offset %d to %d bytes; lost [%d, %d), re-reading [%d, %d)\n",
+				n.Path, n.Offset, tr.Size, tr.Lost.Start, tr.Lost.End, tr.Reread.Start, tr.Reread.End)
+		},
+	}
+	if *cursorFile != "" {
+		cp, err := vrclog.NewCheckpointer(vrclog.NewFileCursorStore(*cursorFile), vrclog.CheckpointPolicy{Interval: time.Second})
+		if err != nil {
+			fmt.Fprintf(stderr, "vrclog: %v\n", err)
+			return 1
+		}
+		cfg.Checkpointer = cp
+	}
+
 	hadFatalError := false
-	for record, err := range vrclog.Follow(ctx, vrclog.FollowConfig{Directory: logDir}) {
+	for record, err := range vrclog.Follow(ctx, cfg) {
 		if err != nil {
 			fmt.Fprintf(stderr, "vrclog: %v\n", err)
 			hadFatalError = true
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	vrclog "github.com/vrclog/vrclog-go"
+)
+
+func cmdLs(args []string) {
+	os.Exit(runLs(context.Background(), args, os.Stdout, os.Stderr))
+}
+
+// lsEntry is one line of `vrclog ls --json`.
+type lsEntry struct {
+	*vrclog.LogFile
+	Stats *vrclog.LogFileStats `json:"stats,omitempty"`
+}
+
+func runLs(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("ls", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	dir := fs.String("dir", "", "log directory path")
+	archives := fs.Bool("archives", false, "also list gzip and zip archived logs")
+	tz := fs.String("tz", "", "IANA time zone the logs were written in (default: local)")
+	header := fs.String("header", "vrchat", "log header format: vrchat, ms, beta or unity")
+	stats := fs.Bool("stats", false, "read each log for its line count, first and last times and completeness")
+	asJSON := fs.Bool("json", false, "print one JSON object per log instead of a table")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	loc, err := loadLocation(*tz)
+	if err != nil {
+		fmt.Fprintf(stderr, "vrclog: %v\n", err)
+		return 2
+	}
+	headers, err := loadHeaderDecoder(*header)
+	if err != nil {
+		fmt.Fprintf(stderr, "vrclog: %v\n", err)
+		return 2
+	}
+
+	files, err := vrclog.ListLogFiles(vrclog.ListLogFilesConfig{
+		Directory:       *dir,
+		IncludeArchives: *archives,
+		Location:        loc,
+		Header:          headers,
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "vrclog: %v\n", err)
+		return 1
+	}
+
+	entries := make([]lsEntry, len(files))
+	exitCode := 0
+	for i, f := range files {
+		entries[i].LogFile = f
+		if !*stats {
+			continue
+		}
+		s, err := f.Stats(ctx)
+		if err != nil {
+			fmt.Fprintf(stderr, "vrclog: %s: %v\n", f.Path, err)
+			exitCode = 1
+			continue
+		}
+		entries[i].Stats = &s
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		for _, e := range entries {
+			if err := enc.Encode(e); err != nil {
+				fmt.Fprintf(stderr, "vrclog: %v\n", err)
+				return 1
+			}
+		}
+		return exitCode
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSIZE\tSTARTED\tFIRST\tLAST\tLINES\tSTATE")
+	for _, e := range entries {
+		first, last, lines := "-", "-", "-"
+		if e.Stats != nil {
+			first, last = formatLsTime(e.Stats.FirstTime), formatLsTime(e.Stats.LastTime)
+			lines = strconv.FormatUint(e.Stats.Lines, 10)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%s\t%s\n", e.Name, e.Size, formatLsTime(e.Started), first, last, lines, lsState(e))
+	}
+	if err := tw.Flush(); err != nil {
+		fmt.Fprintf(stderr, "vrclog: %v\n", err)
+		return 1
+	}
+	return exitCode
+}
+
+// lsState describes a log in the STATE column.
+func lsState(e lsEntry) string {
+	switch {
+	case e.Active:
+		return "active"
+	case e.Stats == nil:
+		return "-"
+	case e.Stats.Complete:
+		return "complete"
+	default:
+		return "incomplete"
+	}
+}
+
+func formatLsTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format(time.DateTime)
+}
@@ -3,6 +3,9 @@ package main
 import (
 	"fmt"
 	"os"
+	"time"
+
+	"github.com/vrclog/vrclog-go"
 )
 
 func main() {
@@ -15,6 +18,12 @@ func main() {
 		cmdRead(os.Args[2:])
 	case "follow":
 		cmdFollow(os.Args[2:])
+	case "session":
+		cmdSession(os.Args[2:])
+	case "ls":
+		cmdLs(os.Args[2:])
+	case "dir":
+		cmdDir(os.Args[2:])
 	case "version":
 		cmdVersion()
 	default:
@@ -24,5 +33,35 @@ func main() {
 }
 
 func usage() {
-	fmt.Fprintln(os.Stderr, "usage: vrclog <read|follow|version> [flags]")
+	fmt.Fprintln(os.Stderr, "usage: vrclog <read|follow|ls|session|dir|version> [flags]")
+}
+
+// loadLocation resolves a --tz flag value. An empty name means the
+// local time zone and is returned as nil, the library default.
+func loadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return nil, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("time zone %q: %w", name, err)
+	}
+	return loc, nil
+}
+
+// headerDecoders maps --header flag values to header decoders.
+var headerDecoders = map[string]vrclog.HeaderDecoder{
+	"vrchat": vrclog.DefaultHeaderDecoder,
+	"ms":     vrclog.MillisecondHeaderDecoder,
+	"beta":   vrclog.BetaHeaderDecoder,
+	"unity":  vrclog.UnityEditorHeaderDecoder,
+}
+
+// loadHeaderDecoder resolves a --header flag value.
+func loadHeaderDecoder(name string) (vrclog.HeaderDecoder, error) {
+	d, ok := headerDecoders[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown header format %q (want vrchat, ms, beta or unity)", name)
+	}
+	return d, nil
 }
@@ -1,16 +1,23 @@
 package main
 
 import (
+	"archive/zip"
 	"bytes"
 	"context"
 	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	vrclog "github.com/vrclog/vrclog-go"
 )
 
 func TestRunReadWithFixture(t *testing.T) {
 	var stdout, stderr bytes.Buffer
-	code := runRead([]string{"../../testdata/logs/vrchat_full.txt"}, &stdout, &stderr)
+	code := runRead([]string{"../../testdata/logs/vrchat_full.txt"}, nil, &stdout, &stderr)
 	if code != 0 {
 		t.Fatalf("expected exit code 0, got %d; stderr: %s", code, stderr.String())
 	}
@@ -30,7 +37,7 @@ func TestRunReadWithFixture(t *testing.T) {
 
 func TestRunReadNonexistentFile(t *testing.T) {
 	var stdout, stderr bytes.Buffer
-	code := runRead([]string{"/nonexistent/path/file.txt"}, &stdout, &stderr)
+	code := runRead([]string{"/nonexistent/path/file.txt"}, nil, &stdout, &stderr)
 	if code != 1 {
 		t.Fatalf("expected exit code 1, got %d", code)
 	}
@@ -41,7 +48,7 @@ func TestRunReadNonexistentFile(t *testing.T) {
 
 func TestRunReadNoArgs(t *testing.T) {
 	var stdout, stderr bytes.Buffer
-	code := runRead([]string{}, &stdout, &stderr)
+	code := runRead([]string{}, nil, &stdout, &stderr)
 	if code != 2 {
 		t.Fatalf("expected exit code 2, got %d", code)
 	}
@@ -52,7 +59,7 @@ func TestRunReadMultipleFilesWithOneBad(t *testing.T) {
 	code := runRead([]string{
 		"../../testdata/logs/vrchat_full.txt",
 		"/nonexistent/file.txt",
-	}, &stdout, &stderr)
+	}, nil, &stdout, &stderr)
 	if code != 1 {
 		t.Fatalf("expected exit code 1, got %d", code)
 	}
@@ -86,3 +93,244 @@ func TestRunFollowFlagParsing(t *testing.T) {
 		t.Fatalf("expected exit code 2 for invalid flag, got %d", code)
 	}
 }
+
+func TestRunReadTimeZone(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := runRead([]string{"--tz", "Asia/Tokyo", "../../testdata/logs/vrchat_full.txt"}, nil, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d; stderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "+09:00\"") {
+		t.Errorf("expected +09:00 timestamps in output, got:\n%s", stdout.String())
+	}
+}
+
+func TestRunReadStdin(t *testing.T) {
+	const fixture = "../../testdata/logs/vrchat_full.txt"
+	data, err := os.ReadFile(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want, wantErr bytes.Buffer
+	if code := runRead([]string{fixture}, nil, &want, &wantErr); code != 0 {
+		t.Fatalf("file read exit code %d; stderr: %s", code, wantErr.String())
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := runRead([]string{"--source-path", fixture, "-"}, bytes.NewReader(data), &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d; stderr: %s", code, stderr.String())
+	}
+	if stdout.Len() == 0 || stdout.String() != want.String() {
+		t.Errorf("stdin output differs from reading the file:\n%s\nwant:\n%s", stdout.String(), want.String())
+	}
+}
+
+func TestRunReadUnknownTimeZone(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := runRead([]string{"--tz", "Nowhere/Unknown", "../../testdata/logs/vrchat_full.txt"}, nil, &stdout, &stderr)
+	if code != 2 {
+		t.Fatalf("expected exit code 2 for unknown time zone, got %d", code)
+	}
+}
+
+func TestRunReadHeaderFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Editor.log")
+	content := "2026-08-18T12:00:00.250Z|0x1a2b|[Behaviour] Entering Room: Test World\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := runRead([]string{path}, nil, &stdout, &stderr); code != 0 || stdout.Len() != 0 {
+		t.Fatalf("default header: code %d, output %q; want no observations", code, stdout.String())
+	}
+	stdout.Reset()
+	if code := runRead([]string{"--header", "unity", path}, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d; stderr: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Test World") {
+		t.Errorf("output = %q, want the world entry", stdout.String())
+	}
+
+	if code := runRead([]string{"--header", "nope", path}, nil, &stdout, &stderr); code != 2 {
+		t.Errorf("expected exit code 2 for unknown header format, got %d", code)
+	}
+}
+
+func TestRunFollowUnknownTruncationPolicy(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := runFollow(context.Background(), []string{"--dir", t.TempDir(), "--truncation", "ignore"}, &stdout, &stderr)
+	if code != 2 {
+		t.Fatalf("expected exit code 2 for unknown truncation policy, got %d", code)
+	}
+}
+
+func TestRunFollowCursorFile(t *testing.T) {
+	dir := t.TempDir()
+	content := "2026.01.15 12:00:00 Debug      -  [Behaviour] OnPlayerJoined TestUser\n"
+	if err := os.WriteFile(filepath.Join(dir, "output_log_2026-01-15_12-00-00.txt"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cursorFile := filepath.Join(t.TempDir(), "cursor.json")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	code := runFollow(ctx, []string{"--dir", dir, "--cursor-file", cursorFile}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d; stderr: %s", code, stderr.String())
+	}
+
+	cursor, err := vrclog.NewFileCursorStore(cursorFile).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cursor == nil || cursor.Offset != int64(len(content)) || cursor.Line != 2 {
+		t.Fatalf("cursor = %+v, want offset %d line 2", cursor, len(content))
+	}
+}
+
+func TestRunDirEnv(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(vrclog.LogDirectoryEnv, dir)
+	want, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := runDir([]string{"--explain"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d; stderr: %s", code, stderr.String())
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if lines[0] != want || len(lines) != 2 || !strings.Contains(lines[1], "selected") {
+		t.Errorf("output = %q, want the directory and the env rule selected", stdout.String())
+	}
+
+	stdout.Reset()
+	if code := runDir([]string{"--json"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d", code)
+	}
+	var res vrclog.LogDirectoryResolution
+	if err := json.Unmarshal(stdout.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Dir != want || res.Rule != vrclog.LogDirectoryFromEnv {
+		t.Errorf("resolution = %+v, want %s from env", res, want)
+	}
+}
+
+func TestRunDirInvalidEnv(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing")
+	t.Setenv(vrclog.LogDirectoryEnv, missing)
+
+	for _, args := range [][]string{nil, {"--json"}} {
+		var stdout, stderr bytes.Buffer
+		if code := runDir(args, &stdout, &stderr); code != 1 {
+			t.Fatalf("%v: expected exit code 1, got %d", args, code)
+		}
+		first, _, _ := strings.Cut(stderr.String(), "\n")
+		if !strings.HasPrefix(first, "vrclog: ") || !strings.Contains(first, missing) {
+			t.Errorf("%v: stderr = %q, want the error about %s", args, stderr.String(), missing)
+		}
+	}
+}
+
+func TestRunLs(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"output_log_2026-01-15_12-00-00.txt": "2026.01.15 12:00:01 Log        -  a\n2026.01.15 12:00:02 Log        -  b\n",
+		"output_log_2026-01-16_12-00-00.txt": "2026.01.16 12:00:01 Log        -  c\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := runLs(context.Background(), []string{"--dir", dir, "--tz", "UTC", "--stats"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d; stderr: %s", code, stderr.String())
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "NAME") {
+		t.Fatalf("table = %q, want a header and two rows", stdout.String())
+	}
+	if !strings.Contains(lines[1], "2026-01-15 12:00:02") || !strings.HasSuffix(lines[1], "complete") || !strings.HasSuffix(lines[2], "active") {
+		t.Errorf("table = %q", stdout.String())
+	}
+
+	stdout.Reset()
+	if code := runLs(context.Background(), []string{"--dir", dir, "--json"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d; stderr: %s", code, stderr.String())
+	}
+	var entry map[string]any
+	first, _, _ := strings.Cut(stdout.String(), "\n")
+	if err := json.Unmarshal([]byte(first), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry["name"] != "output_log_2026-01-15_12-00-00.txt" || entry["stats"] != nil {
+		t.Errorf("entry = %v, want the oldest log without stats", entry)
+	}
+}
+
+func TestRunSession(t *testing.T) {
+	dir := t.TempDir()
+	content := "2026.01.15 12:00:00 Debug      -  VRChat Build: 2026.1.1p1-1600--Release\n" +
+		"2026.01.15 12:00:00 Debug      -  Unity Version: 2022.3.22f1-DWR\n"
+	if err := os.WriteFile(filepath.Join(dir, "output_log_2026-01-15_12-00-00.txt"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := runSession(context.Background(), []string{"--dir", dir}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d; stderr: %s", code, stderr.String())
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], "2026.1.1p1-1600--Release") {
+		t.Errorf("table = %q", stdout.String())
+	}
+
+	stdout.Reset()
+	path := filepath.Join(dir, "output_log_2026-01-15_12-00-00.txt")
+	if code := runSession(context.Background(), []string{"--json", path}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d; stderr: %s", code, stderr.String())
+	}
+	var info vrclog.SessionInfo
+	if err := json.Unmarshal(stdout.Bytes(), &info); err != nil {
+		t.Fatal(err)
+	}
+	if info.ClientBuild != "2026.1.1p1-1600--Release" || info.UnityVersion != "2022.3.22f1-DWR" {
+		t.Errorf("session = %+v", info)
+	}
+}
+
+func TestRunSessionArchiveEntryNames(t *testing.T) {
+	dir := t.TempDir()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range []string{"output_log_2026-01-15_12-00-00.txt", "output_log_2026-01-16_12-00-00.txt"} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(w, "2026.01.15 12:00:00 Debug      -  VRChat Build: 2026.1.1p1-1600--Release\n")
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "logs.zip"), buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := runSession(context.Background(), []string{"--dir", dir, "--archives"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d; stderr: %s", code, stderr.String())
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[1], "output_log_2026-01-15_12-00-00.txt") || !strings.HasPrefix(lines[2], "output_log_2026-01-16_12-00-00.txt") {
+		t.Errorf("table = %q, want a row named after each entry", stdout.String())
+	}
+}
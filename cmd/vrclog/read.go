@@ -11,22 +11,49 @@ import (
 )
 
 func cmdRead(args []string) {
-	os.Exit(runRead(args, os.Stdout, os.Stderr))
+	os.Exit(runRead(args, os.Stdin, os.Stdout, os.Stderr))
 }
 
-func runRead(args []string, stdout, stderr io.Writer) int {
+// stdinPath is the file argument that makes read use stdin.
+const stdinPath = "-"
+
+func runRead(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
 	fs := flag.NewFlagSet("read", flag.ContinueOnError)
 	fs.SetOutput(stderr)
+	tz := fs.String("tz", "", "IANA time zone the logs were written in (default: local)")
+	header := fs.String("header", "vrchat", "log header format: vrchat, ms, beta or unity")
+	sourcePath := fs.String("source-path", "", "original path of the log read from stdin, for its SourceID")
 	if err := fs.Parse(args); err != nil {
 		return 2
 	}
 
+	loc, err := loadLocation(*tz)
+	if err != nil {
+		fmt.Fprintf(stderr, "vrclog: %v\n", err)
+		return 2
+	}
+	headers, err := loadHeaderDecoder(*header)
+	if err != nil {
+		fmt.Fprintf(stderr, "vrclog: %v\n", err)
+		return 2
+	}
+
 	paths := fs.Args()
 	if len(paths) == 0 {
-		fmt.Fprintln(stderr, "usage: vrclog read <file> [<file>...]")
+		fmt.Fprintln(stderr, "usage: vrclog read <file|-> [<file>...]")
 		return 2
 	}
 
+	stdinCfg := vrclog.ReaderConfig{SourceID: "stdin", Path: stdinPath, Location: loc, Header: headers}
+	if *sourcePath != "" {
+		id, err := vrclog.SourceIDForPath(*sourcePath)
+		if err != nil {
+			fmt.Fprintf(stderr, "vrclog: %v\n", err)
+			return 2
+		}
+		stdinCfg.SourceID, stdinCfg.Path = id, *sourcePath
+	}
+
 	engine, err := vrclog.NewEngine(vrclog.NewVRChatAdapter())
 	if err != nil {
 		fmt.Fprintf(stderr, "vrclog: engine init: %v\n", err)
@@ -37,7 +64,11 @@ func runRead(args []string, stdout, stderr io.Writer) int {
 	hadFatalError := false
 
 	for _, path := range paths {
-		for record, err := range vrclog.ReadFile(ctx, vrclog.ReadFileConfig{Path: path}) {
+		records := vrclog.ReadFile(ctx, vrclog.ReadFileConfig{Path: path, Location: loc, Header: headers})
+		if path == stdinPath {
+			records = vrclog.ReadRecords(ctx, stdin, stdinCfg)
+		}
+		for record, err := range records {
 			if err != nil {
 				fmt.Fprintf(stderr, "vrclog: %s: %v\n", path, err)
 				hadFatalError = true
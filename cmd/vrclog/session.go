@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	vrclog "github.com/vrclog/vrclog-go"
+)
+
+func cmdSession(args []string) {
+	os.Exit(runSession(context.Background(), args, os.Stdout, os.Stderr))
+}
+
+// sessionLog is one log `vrclog session` reads, with the name the table
+// shows for it: that of the original .txt file for an archived log.
+type sessionLog struct {
+	cfg  vrclog.ReadFileConfig
+	name string
+	info vrclog.SessionInfo
+}
+
+// runSession prints the SessionInfo of the given log files, or of every
+// log in the log directory if none are given.
+func runSession(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("session", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	dir := fs.String("dir", "", "log directory path, used when no files are given")
+	archives := fs.Bool("archives", false, "with --dir, also read gzip and zip archived logs")
+	tz := fs.String("tz", "", "IANA time zone the logs were written in (default: local)")
+	header := fs.String("header", "vrchat", "log header format: vrchat, ms, beta or unity")
+	asJSON := fs.Bool("json", false, "print one JSON object per log instead of a table")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	loc, err := loadLocation(*tz)
+	if err != nil {
+		fmt.Fprintf(stderr, "vrclog: %v\n", err)
+		return 2
+	}
+	headers, err := loadHeaderDecoder(*header)
+	if err != nil {
+		fmt.Fprintf(stderr, "vrclog: %v\n", err)
+		return 2
+	}
+
+	var logs []sessionLog
+	for _, path := range fs.Args() {
+		name := strings.TrimSuffix(filepath.Base(path), ".gz")
+		logs = append(logs, sessionLog{cfg: vrclog.ReadFileConfig{Path: path}, name: name})
+	}
+	if len(logs) == 0 {
+		files, err := vrclog.ListLogFiles(vrclog.ListLogFilesConfig{Directory: *dir, IncludeArchives: *archives})
+		if err != nil {
+			fmt.Fprintf(stderr, "vrclog: %v\n", err)
+			return 1
+		}
+		for _, f := range files {
+			logs = append(logs, sessionLog{cfg: vrclog.ReadFileConfig{Path: f.Path, Entry: f.Entry}, name: f.Name})
+		}
+	}
+
+	var read []sessionLog
+	exitCode := 0
+	for _, l := range logs {
+		l.cfg.Location, l.cfg.Header = loc, headers
+		info, err := vrclog.ReadSessionInfo(ctx, l.cfg)
+		if err != nil {
+			fmt.Fprintf(stderr, "vrclog: %s: %v\n", l.cfg.Path, err)
+			exitCode = 1
+			continue
+		}
+		l.info = info
+		read = append(read, l)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		for _, l := range read {
+			if err := enc.Encode(l.info); err != nil {
+				fmt.Fprintf(stderr, "vrclog: %v\n", err)
+				return 1
+			}
+		}
+		return exitCode
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tBUILD\tUNITY\tDEVICE\tCOMMAND LINE")
+	for _, l := range read {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", l.name,
+			orDash(l.info.ClientBuild), orDash(l.info.UnityVersion), orDash(l.info.DeviceModel), orDash(l.info.CommandLine))
+	}
+	if err := tw.Flush(); err != nil {
+		fmt.Fprintf(stderr, "vrclog: %v\n", err)
+		return 1
+	}
+	return exitCode
+}
+
+// orDash returns s, or "-" for an empty table cell.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,334 @@
+package vrclog
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/vrclog/vrclog-go/internal/logfile"
+)
+
+// cursorHeadSize is the number of leading bytes of a file a cursor
+// fingerprint hashes. VRChat's first lines carry the session start time
+// and client details, so this is unique per log in practice.
+const cursorHeadSize = 4096
+
+// cursorTokenPrefix identifies the cursor token format version.
+const cursorTokenPrefix = "v1."
+
+// CursorFingerprint identifies the content a Cursor was taken from, so
+// that resuming can tell a file replaced at the same path from the
+// original.
+type CursorFingerprint struct {
+	// HeadSize is the number of leading bytes of the file hashed into
+	// Head, at most 4 KiB.
+	HeadSize int64 `json:"head_size"`
+	// Head is the hex SHA-256 of the first HeadSize bytes.
+	Head string `json:"head"`
+
+	// TailOffset is the offset of the line ending at Cursor.Offset and
+	// Tail is that line's RecordID. Tail is empty if Cursor.Offset is 0.
+	TailOffset int64    `json:"tail_offset"`
+	Tail       RecordID `json:"tail,omitempty"`
+}
+
+// IsZero reports whether fp is absent.
+func (fp CursorFingerprint) IsZero() bool {
+	return fp == CursorFingerprint{}
+}
+
+// sourceHead is the hashed head of a log file at the time a reader
+// looked at it.
+type sourceHead struct {
+	size int64
+	hash string
+	// encoding is the encoding the head's byte order mark announces,
+	// if marked.
+	encoding Encoding
+	marked   bool
+}
+
+// readSourceHead hashes up to cursorHeadSize leading bytes of r. It
+// returns nil for an empty file.
+func readSourceHead(r io.ReaderAt) (*sourceHead, error) {
+	buf := make([]byte, cursorHeadSize)
+	n, err := r.ReadAt(buf, 0)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	return hashSourceHead(buf[:n]), nil
+}
+
+// hashSourceHead hashes head, the leading bytes of a file. It returns nil
+// if head is empty.
+func hashSourceHead(head []byte) *sourceHead {
+	if len(head) == 0 {
+		return nil
+	}
+	head = head[:min(len(head), cursorHeadSize)]
+	sum := sha256.Sum256(head)
+	enc, _, marked := detectEncoding(head)
+	return &sourceHead{size: int64(len(head)), hash: hex.EncodeToString(sum[:]), encoding: enc, marked: marked}
+}
+
+// complete reports whether h already covers cursorHeadSize bytes and so
+// never needs to be read again.
+func (h *sourceHead) complete() bool {
+	return h != nil && h.size >= cursorHeadSize
+}
+
+// verifyCursorContent checks that r, read from the start of the file,
+// still holds the content c's fingerprint was taken from. A cursor
+// without a fingerprint always passes.
+func verifyCursorContent(r io.Reader, c *Cursor) error {
+	fp := c.Fingerprint
+	if fp.IsZero() {
+		return nil
+	}
+	if fp.HeadSize < 0 || fp.HeadSize > cursorHeadSize || fp.TailOffset < 0 || fp.TailOffset > c.Offset {
+		return fmt.Errorf("%w: malformed fingerprint", ErrCursorContentMismatch)
+	}
+
+	head := make([]byte, fp.HeadSize)
+	if _, err := io.ReadFull(r, head); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return fmt.Errorf("%w: file is shorter than the fingerprinted head", ErrCursorContentMismatch)
+		}
+		return err
+	}
+	sum := sha256.Sum256(head)
+	if hex.EncodeToString(sum[:]) != fp.Head {
+		return fmt.Errorf("%w: first %d bytes differ", ErrCursorContentMismatch, fp.HeadSize)
+	}
+
+	if fp.Tail == "" {
+		return nil
+	}
+	var rest io.Reader
+	if fp.TailOffset < fp.HeadSize {
+		rest = io.MultiReader(bytes.NewReader(head[fp.TailOffset:]), r)
+	} else {
+		if _, err := io.CopyN(io.Discard, r, fp.TailOffset-fp.HeadSize); err != nil {
+			if err == io.EOF {
+				return fmt.Errorf("%w: file is shorter than the cursor offset", ErrCursorContentMismatch)
+			}
+			return err
+		}
+		rest = r
+	}
+
+	enc, _, _ := detectEncoding(head)
+	lr := newLineReader(io.LimitReader(rest, c.Offset-fp.TailOffset), fp.TailOffset, LineUnknown, recordOptions{encoding: enc})
+	_, rawHash, _, nextOffset, _, _, _, err := lr.next()
+	if err != nil && err != io.EOF {
+		return err
+	}
+	if err == io.EOF || nextOffset != c.Offset || computeRecordID(c.SourceID, fp.TailOffset, rawHash) != fp.Tail {
+		return fmt.Errorf("%w: line before offset %d differs", ErrCursorContentMismatch, c.Offset)
+	}
+	return nil
+}
+
+// Token encodes c as a compact, versioned string that ParseCursorToken
+// turns back into the same Cursor. A SourceID derivable from Path is
+// left out, and hex hashes are stored as their raw bytes.
+func (c Cursor) Token() string {
+	var b []byte
+	b = appendTokenString(b, c.Path)
+	switch id := string(c.SourceID); {
+	case id != "" && id == hostSourceID(c.Path):
+		b = append(b, tokenHostSource)
+	case id != "" && id == fsSourceID(c.Path):
+		b = append(b, tokenFSSource)
+	default:
+		b = append(b, tokenLiteralSource)
+		b = appendTokenHash(b, string(c.SourceID))
+	}
+	b = binary.AppendVarint(b, c.Offset)
+	b = binary.AppendUvarint(b, c.Line)
+	if fp := c.Fingerprint; !fp.IsZero() {
+		b = append(b, 1)
+		b = binary.AppendVarint(b, fp.HeadSize)
+		b = appendTokenHash(b, fp.Head)
+		b = binary.AppendVarint(b, fp.TailOffset)
+		b = appendTokenHash(b, string(fp.Tail))
+	} else {
+		b = append(b, 0)
+	}
+	return cursorTokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+}
+
+// How a cursor token records the SourceID.
+const (
+	tokenHostSource    = 0 // derived from an absolute host Path
+	tokenFSSource      = 1 // derived from a Path inside an fs.FS
+	tokenLiteralSource = 2 // stored as is
+)
+
+// hostSourceID returns the SourceID of the host file at path, or "" if
+// path is relative and so depends on the working directory.
+func hostSourceID(path string) string {
+	if !filepath.IsAbs(path) {
+		return ""
+	}
+	id, err := logfile.SourceID(path)
+	if err != nil {
+		return ""
+	}
+	return id
+}
+
+// fsSourceID returns the SourceID of the file named name in an fs.FS,
+// or "" if name is not a valid path.
+func fsSourceID(name string) string {
+	if !fs.ValidPath(name) {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(name))
+	return hex.EncodeToString(sum[:])
+}
+
+// ParseCursorToken decodes a string produced by Cursor.Token.
+func ParseCursorToken(token string) (Cursor, error) {
+	payload, ok := strings.CutPrefix(token, cursorTokenPrefix)
+	if !ok {
+		return Cursor{}, fmt.Errorf("%w: unknown version", ErrInvalidCursorToken)
+	}
+	b, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("%w: %v", ErrInvalidCursorToken, err)
+	}
+
+	d := tokenDecoder{b: b}
+	var c Cursor
+	c.Path = d.string()
+	switch d.byte() {
+	case tokenHostSource:
+		c.SourceID = SourceID(hostSourceID(c.Path))
+		if c.SourceID == "" {
+			d.fail()
+		}
+	case tokenFSSource:
+		c.SourceID = SourceID(fsSourceID(c.Path))
+		if c.SourceID == "" {
+			d.fail()
+		}
+	case tokenLiteralSource:
+		c.SourceID = SourceID(d.hash())
+	default:
+		d.fail()
+	}
+	c.Offset = d.varint()
+	c.Line = d.uvarint()
+	switch d.byte() {
+	case 0:
+	case 1:
+		c.Fingerprint.HeadSize = d.varint()
+		c.Fingerprint.Head = d.hash()
+		c.Fingerprint.TailOffset = d.varint()
+		c.Fingerprint.Tail = RecordID(d.hash())
+	default:
+		d.fail()
+	}
+	if d.err != nil || len(d.b) != 0 {
+		return Cursor{}, fmt.Errorf("%w: malformed payload", ErrInvalidCursorToken)
+	}
+	return c, nil
+}
+
+// appendTokenHash appends s, storing a lowercase hex SHA-256 as its 32
+// raw bytes behind a zero tag and anything else as a string behind its
+// length plus one.
+func appendTokenHash(b []byte, s string) []byte {
+	if raw, err := hex.DecodeString(s); err == nil && len(raw) == sha256.Size && hex.EncodeToString(raw) == s {
+		b = append(b, 0)
+		return append(b, raw...)
+	}
+	b = binary.AppendUvarint(b, uint64(len(s))+1)
+	return append(b, s...)
+}
+
+func appendTokenString(b []byte, s string) []byte {
+	b = binary.AppendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+// tokenDecoder reads the fields of a cursor token, remembering the first
+// failure.
+type tokenDecoder struct {
+	b   []byte
+	err error
+}
+
+func (d *tokenDecoder) fail() {
+	if d.err == nil {
+		d.err = errors.New("malformed")
+	}
+	d.b = nil
+}
+
+func (d *tokenDecoder) uvarint() uint64 {
+	v, n := binary.Uvarint(d.b)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.b = d.b[n:]
+	return v
+}
+
+func (d *tokenDecoder) varint() int64 {
+	v, n := binary.Varint(d.b)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.b = d.b[n:]
+	return v
+}
+
+func (d *tokenDecoder) byte() byte {
+	if len(d.b) == 0 {
+		d.fail()
+		return 0
+	}
+	v := d.b[0]
+	d.b = d.b[1:]
+	return v
+}
+
+func (d *tokenDecoder) hash() string {
+	tag := d.uvarint()
+	if tag == 0 {
+		if len(d.b) < sha256.Size {
+			d.fail()
+			return ""
+		}
+		s := hex.EncodeToString(d.b[:sha256.Size])
+		d.b = d.b[sha256.Size:]
+		return s
+	}
+	return d.bytes(tag - 1)
+}
+
+func (d *tokenDecoder) string() string {
+	return d.bytes(d.uvarint())
+}
+
+func (d *tokenDecoder) bytes(n uint64) string {
+	if n > uint64(len(d.b)) {
+		d.fail()
+		return ""
+	}
+	s := string(d.b[:n])
+	d.b = d.b[n:]
+	return s
+}
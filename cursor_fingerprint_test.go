@@ -0,0 +1,272 @@
+package vrclog
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/vrclog/vrclog-go/internal/logfile"
+)
+
+func TestCursorFingerprintSetByReaders(t *testing.T) {
+	dir := t.TempDir()
+	path := writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt",
+		logLine("2024.01.01 00:00:01", "a")+logLine("2024.01.01 00:00:02", "b"))
+
+	records := collectDirectory(t, ReadDirectoryConfig{Directory: dir})
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	c := records[1].Cursor()
+	fp := c.Fingerprint
+	if fp.IsZero() {
+		t.Fatal("cursor has no fingerprint")
+	}
+	if fp.HeadSize != int64(len(logLine("2024.01.01 00:00:01", "a")+logLine("2024.01.01 00:00:02", "b"))) {
+		t.Errorf("HeadSize = %d, want the whole file", fp.HeadSize)
+	}
+	if fp.TailOffset != records[1].Offset || fp.Tail != records[1].ID {
+		t.Errorf("tail = %d/%s, want %d/%s", fp.TailOffset, fp.Tail, records[1].Offset, records[1].ID)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := verifyCursorContent(f, &c); err != nil {
+		t.Errorf("verify unchanged file: %v", err)
+	}
+}
+
+func TestCursorFingerprintJSONOmittedWhenZero(t *testing.T) {
+	data, err := json.Marshal(Cursor{SourceID: "src", Offset: 1, Line: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "fingerprint") {
+		t.Errorf("json = %s, want no fingerprint", data)
+	}
+}
+
+func TestCursorTokenRoundTrip(t *testing.T) {
+	hostPath := filepath.Join(t.TempDir(), "output_log_2024-01-01_00-00-00.txt")
+	hostID, err := logfile.SourceID(hostPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsID, err := logfile.FSRoot(fstest.MapFS{}).SourceID("logs/output_log.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cursors := []Cursor{
+		{SourceID: "src", Path: "/logs/output_log_2024-01-01_00-00-00.txt", Offset: 38, Line: 2},
+		{SourceID: SourceID(hostID), Path: hostPath, Offset: 38, Line: 2},
+		{SourceID: SourceID(fsID), Path: "logs/output_log.txt", Offset: 38, Line: 2},
+		{SourceID: SourceID(strings.Repeat("cd", 32)), Path: "relative/output_log.txt"},
+		{},
+		{
+			SourceID: "src",
+			Path:     `C:\Users\me\output_log.txt`,
+			Offset:   1 << 40,
+			Line:     1 << 33,
+			Fingerprint: CursorFingerprint{
+				HeadSize:   4096,
+				Head:       strings.Repeat("ab", 32),
+				TailOffset: 1<<40 - 10,
+				Tail:       "deadbeef",
+			},
+		},
+		{
+			SourceID:    SourceID(hostID),
+			Path:        hostPath,
+			Fingerprint: CursorFingerprint{HeadSize: 10, Head: strings.Repeat("AB", 32)},
+		},
+	}
+	for _, want := range cursors {
+		token := want.Token()
+		if !strings.HasPrefix(token, cursorTokenPrefix) {
+			t.Errorf("token %q lacks version prefix", token)
+		}
+		got, err := ParseCursorToken(token)
+		if err != nil {
+			t.Fatalf("ParseCursorToken(%q): %v", token, err)
+		}
+		if got != want {
+			t.Errorf("round trip = %+v, want %+v", got, want)
+		}
+	}
+}
+
+func TestCursorTokenCompact(t *testing.T) {
+	path := `C:\Users\me\AppData\LocalLow\VRChat\VRChat\output_log_2024-01-01_00-00-00.txt`
+	if runtime.GOOS != "windows" {
+		path = "/home/me/.steam/steamapps/compatdata/438100/pfx/output_log_2024-01-01_00-00-00.txt"
+	}
+	id, err := logfile.SourceID(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := Cursor{
+		SourceID: SourceID(id),
+		Path:     path,
+		Offset:   123456789,
+		Line:     654321,
+		Fingerprint: CursorFingerprint{
+			HeadSize:   cursorHeadSize,
+			Head:       strings.Repeat("0f", 32),
+			TailOffset: 123456700,
+			Tail:       RecordID(strings.Repeat("e1", 32)),
+		},
+	}
+
+	// The path, two raw hashes and a few varints; the SourceID and the
+	// hex spelling of the hashes are not stored.
+	payload := len(path) + 2*sha256.Size + 24
+	if token := c.Token(); len(token) > len(cursorTokenPrefix)+base64.RawURLEncoding.EncodedLen(payload) {
+		t.Errorf("token is %d characters: %s", len(token), token)
+	}
+}
+
+func TestParseCursorTokenInvalid(t *testing.T) {
+	valid := Cursor{SourceID: "src", Offset: 1, Line: 2}.Token()
+	tokens := []string{
+		"",
+		"v2." + strings.TrimPrefix(valid, cursorTokenPrefix),
+		cursorTokenPrefix + "!!!",
+		valid[:len(valid)-2],
+		valid + "AA",
+	}
+	for _, token := range tokens {
+		if _, err := ParseCursorToken(token); !errors.Is(err, ErrInvalidCursorToken) {
+			t.Errorf("ParseCursorToken(%q) error = %v, want ErrInvalidCursorToken", token, err)
+		}
+	}
+}
+
+func TestFollow_CursorRejectsReplacedFile(t *testing.T) {
+	dir := t.TempDir()
+	name := "output_log_2024-01-01_00-00-00.txt"
+	path := writeLogFile(t, dir, name,
+		logLine("2024.01.01 00:00:01", "first")+logLine("2024.01.01 00:00:02", "second"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	first, _ := collectRecords(t, ctx, FollowConfig{Directory: dir, PollInterval: testPollInterval}, 1)
+	cursor := first[0].Cursor()
+
+	// Same path, same length, different content.
+	if err := os.WriteFile(path, []byte(logLine("2024.01.01 00:00:01", "FIRST")+logLine("2024.01.01 00:00:02", "second")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, errs := collectRecords(t, ctx, FollowConfig{Directory: dir, PollInterval: testPollInterval, Cursor: &cursor}, 1)
+	if len(errs) != 1 || !errors.Is(errs[0], ErrCursorContentMismatch) {
+		t.Fatalf("errors = %v, want ErrCursorContentMismatch", errs)
+	}
+}
+
+func TestFollow_CursorAcceptsAppendedFile(t *testing.T) {
+	dir := t.TempDir()
+	path := writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt", logLine("2024.01.01 00:00:01", "first"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	first, _ := collectRecords(t, ctx, FollowConfig{Directory: dir, PollInterval: testPollInterval}, 1)
+	cursor, err := ParseCursorToken(first[0].Cursor().Token())
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendToFile(t, path, logLine("2024.01.01 00:00:02", "second"))
+
+	records, errs := collectRecords(t, ctx, FollowConfig{Directory: dir, PollInterval: testPollInterval, Cursor: &cursor}, 1)
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if len(records) != 1 || records[0].Message != "second" {
+		t.Fatalf("records = %+v, want only \"second\"", records)
+	}
+}
+
+func TestReadDirectory_CursorRejectsReplacedFile(t *testing.T) {
+	dir := t.TempDir()
+	path := writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt",
+		logLine("2024.01.01 00:00:01", "a")+logLine("2024.01.01 00:00:02", "b"))
+
+	records := collectDirectory(t, ReadDirectoryConfig{Directory: dir})
+	cursor := records[0].Cursor()
+
+	if err := os.WriteFile(path, []byte(logLine("2024.01.01 00:00:09", "x")+logLine("2024.01.01 00:00:02", "b")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var gotErr error
+	for _, err := range ReadDirectory(context.Background(), ReadDirectoryConfig{Directory: dir, Cursor: &cursor}) {
+		if err != nil {
+			gotErr = err
+			break
+		}
+	}
+	if !errors.Is(gotErr, ErrCursorContentMismatch) {
+		t.Fatalf("error = %v, want ErrCursorContentMismatch", gotErr)
+	}
+}
+
+func TestFollow_CursorAfterTruncationRewind(t *testing.T) {
+	dir := t.TempDir()
+	// The old head covers cursorHeadSize bytes, so it would never be
+	// re-read unless the rewind drops it.
+	var b strings.Builder
+	for b.Len() < 2*cursorHeadSize {
+		b.WriteString(logLine("2024.01.01 00:00:01", "old"))
+	}
+	old := b.String()
+	path := writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt", old)
+	n := strings.Count(old, "\n")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var rewound Record
+	count := 0
+	for rec, err := range Follow(ctx, FollowConfig{Directory: dir, PollInterval: testPollInterval, Truncation: TruncationRewind}) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		count++
+		if count == n {
+			if err := os.WriteFile(path, []byte(logLine("2024.01.01 00:00:02", "new")), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if count > n {
+			rewound = rec
+			break
+		}
+	}
+	if rewound.Message != "new" {
+		t.Fatalf("record after rewind = %+v, want \"new\"", rewound)
+	}
+
+	cursor := rewound.Cursor()
+	appendToFile(t, path, logLine("2024.01.01 00:00:03", "after"))
+	records, errs := collectRecords(t, ctx, FollowConfig{Directory: dir, PollInterval: testPollInterval, Cursor: &cursor}, 1)
+	if len(errs) != 0 {
+		t.Fatalf("resume after rewind: %v", errs)
+	}
+	if len(records) != 1 || records[0].Message != "after" {
+		t.Fatalf("records = %+v, want only \"after\"", records)
+	}
+}
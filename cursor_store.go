@@ -0,0 +1,286 @@
+package vrclog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// CursorStore persists a Follow position across process restarts.
+type CursorStore interface {
+	// Load returns the most recently saved cursor, or nil if nothing
+	// has been saved yet.
+	Load() (*Cursor, error)
+	Save(cursor Cursor) error
+}
+
+// FileCursorStore stores a cursor as JSON in a single file. Save writes
+// a temporary file in the same directory, fsyncs it, renames it over
+// the target and fsyncs the directory, so a crash leaves either the
+// previous or the new cursor on disk, never a torn one.
+type FileCursorStore struct {
+	path string
+}
+
+func NewFileCursorStore(path string) *FileCursorStore {
+	return &FileCursorStore{path: path}
+}
+
+func (s *FileCursorStore) Load() (*Cursor, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("decode cursor file %s: %w", s.path, err)
+	}
+	return &c, nil
+}
+
+func (s *FileCursorStore) Save(cursor Cursor) error {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	dir := filepath.Dir(s.path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(s.path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	committed := false
+	defer func() {
+		if !committed {
+			tmp.Close()
+			os.Remove(tmpName)
+		}
+	}()
+
+	if err := tmp.Chmod(0o600); err != nil && runtime.GOOS != "windows" {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpName, s.path); err != nil {
+		return err
+	}
+	committed = true
+
+	return syncDir(dir)
+}
+
+// syncDir makes a completed rename durable. Windows cannot fsync a
+// directory handle; NTFS journals the rename itself.
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// CheckpointPolicy decides when acknowledged records are saved to a
+// CursorStore. With EveryRecords and Interval both zero, every
+// acknowledgement is saved immediately.
+type CheckpointPolicy struct {
+	// EveryRecords saves once this many records have been acknowledged
+	// since the last save.
+	EveryRecords int
+
+	// Interval saves on the first acknowledgement at least this long
+	// after the last save. While Follow waits for a quiet log, it saves
+	// an unsaved acknowledgement as soon as Interval has passed.
+	Interval time.Duration
+
+	// ManualAck stops Follow from acknowledging records itself. The
+	// consumer calls Checkpointer.Ack once a record is fully handled.
+	// Otherwise Follow acknowledges a record as soon as the range loop
+	// body it was yielded to returns and asks for the next one.
+	ManualAck bool
+}
+
+// Checkpointer tracks the latest acknowledged record and saves its
+// cursor according to a CheckpointPolicy. Ack and Flush are safe for
+// concurrent use.
+type Checkpointer struct {
+	store  CursorStore
+	policy CheckpointPolicy
+
+	mu       sync.Mutex
+	pending  *Cursor
+	unsaved  int
+	lastSave time.Time
+}
+
+func NewCheckpointer(store CursorStore, policy CheckpointPolicy) (*Checkpointer, error) {
+	if store == nil {
+		return nil, errors.New("cursor store must not be nil")
+	}
+	if policy.EveryRecords < 0 {
+		return nil, errors.New("checkpoint record count must not be negative")
+	}
+	if policy.Interval < 0 {
+		return nil, errors.New("checkpoint interval must not be negative")
+	}
+	return &Checkpointer{store: store, policy: policy, lastSave: time.Now()}, nil
+}
+
+// Load returns the cursor saved in the underlying store, if any.
+func (c *Checkpointer) Load() (*Cursor, error) {
+	return c.store.Load()
+}
+
+// Ack marks record as fully handled and saves its cursor if the policy
+// says a checkpoint is due.
+func (c *Checkpointer) Ack(record Record) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cursor := record.Cursor()
+	c.pending = &cursor
+	c.unsaved++
+
+	due := false
+	switch {
+	case c.policy.EveryRecords == 0 && c.policy.Interval == 0:
+		due = true
+	case c.policy.EveryRecords > 0 && c.unsaved >= c.policy.EveryRecords:
+		due = true
+	case c.policy.Interval > 0 && time.Since(c.lastSave) >= c.policy.Interval:
+		due = true
+	}
+	if !due {
+		return nil
+	}
+	return c.saveLocked()
+}
+
+// Flush saves the latest acknowledged cursor if it has not been saved
+// yet. Follow calls it when it returns.
+func (c *Checkpointer) Flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.unsaved == 0 {
+		return nil
+	}
+	return c.saveLocked()
+}
+
+func (c *Checkpointer) saveLocked() error {
+	if err := c.store.Save(*c.pending); err != nil {
+		return err
+	}
+	c.unsaved = 0
+	c.lastSave = time.Now()
+	return nil
+}
+
+// untilDue returns how long until an Interval checkpoint of the latest
+// acknowledged record is due. ok is false if no such checkpoint is
+// pending.
+func (c *Checkpointer) untilDue() (d time.Duration, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.policy.Interval == 0 || c.unsaved == 0 {
+		return 0, false
+	}
+	return max(c.policy.Interval-time.Since(c.lastSave), 0), true
+}
+
+// flushDue saves the latest acknowledged cursor if an Interval
+// checkpoint of it is due, so that a quiet log does not keep it unsaved
+// until the next record.
+func (c *Checkpointer) flushDue() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.policy.Interval == 0 || c.unsaved == 0 || time.Since(c.lastSave) < c.policy.Interval {
+		return nil
+	}
+	return c.saveLocked()
+}
+
+// checkpointRun acknowledges the records of one Follow to cp. Its yield
+// method wraps the consumer's yield so that every record the consumer
+// finishes with is acknowledged; finish flushes cp and must be deferred
+// by the caller. A save error is reported only while the consumer is
+// still iterating.
+type checkpointRun struct {
+	cp      *Checkpointer
+	next    func(Record, error) bool
+	stopped bool
+}
+
+func (r *checkpointRun) yield(rec Record, err error) bool {
+	if !r.next(rec, err) {
+		r.stopped = true
+		return false
+	}
+	if err == nil && !r.cp.policy.ManualAck {
+		if ackErr := r.cp.Ack(rec); ackErr != nil {
+			r.fail(ackErr)
+			return false
+		}
+	}
+	return true
+}
+
+func (r *checkpointRun) finish() {
+	if err := r.cp.Flush(); err != nil && !r.stopped {
+		r.next(Record{}, fmt.Errorf("checkpoint: %w", err))
+	}
+}
+
+func (r *checkpointRun) fail(err error) {
+	r.next(Record{}, fmt.Errorf("checkpoint: %w", err))
+	r.stopped = true
+}
+
+// checkpointWaiter is a changeWaiter that also wakes when an Interval
+// checkpoint is due, and saves it, so that records acknowledged before
+// the log went quiet are saved on time. wait returns false if the save
+// failed.
+type checkpointWaiter struct {
+	changeWaiter
+	run *checkpointRun
+}
+
+func (w checkpointWaiter) wait(ctx context.Context) bool {
+	d, ok := w.run.cp.untilDue()
+	if !ok {
+		return w.changeWaiter.wait(ctx)
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+	w.changeWaiter.wait(waitCtx)
+	if ctx.Err() != nil {
+		return false
+	}
+	if err := w.run.cp.flushDue(); err != nil {
+		w.run.fail(err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,274 @@
+package vrclog
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type memCursorStore struct {
+	saved   *Cursor
+	saves   int
+	saveErr error
+}
+
+func (m *memCursorStore) Load() (*Cursor, error) { return m.saved, nil }
+
+func (m *memCursorStore) Save(c Cursor) error {
+	if m.saveErr != nil {
+		return m.saveErr
+	}
+	m.saved = &c
+	m.saves++
+	return nil
+}
+
+func TestFileCursorStore_LoadMissingReturnsNil(t *testing.T) {
+	store := NewFileCursorStore(filepath.Join(t.TempDir(), "cursor.json"))
+
+	c, err := store.Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c != nil {
+		t.Fatalf("expected nil cursor, got %+v", c)
+	}
+}
+
+func TestFileCursorStore_SaveLoadRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileCursorStore(filepath.Join(dir, "cursor.json"))
+
+	want := Cursor{SourceID: "abc", Path: "/logs/output_log.txt", Offset: 42, Line: 3}
+	if err := store.Save(want); err != nil {
+		t.Fatal(err)
+	}
+	want.Offset = 84
+	want.Line = 5
+	if err := store.Save(want); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || *got != want {
+		t.Fatalf("Load() = %+v, want %+v", got, want)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected only the cursor file to remain, found %d entries", len(entries))
+	}
+}
+
+func TestFileCursorStore_LoadCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cursor.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewFileCursorStore(path).Load(); err == nil {
+		t.Fatal("expected decode error for corrupt cursor file")
+	}
+}
+
+func TestNewCheckpointer_Validation(t *testing.T) {
+	if _, err := NewCheckpointer(nil, CheckpointPolicy{}); err == nil {
+		t.Error("expected error for nil store")
+	}
+	if _, err := NewCheckpointer(&memCursorStore{}, CheckpointPolicy{EveryRecords: -1}); err == nil {
+		t.Error("expected error for negative record count")
+	}
+	if _, err := NewCheckpointer(&memCursorStore{}, CheckpointPolicy{Interval: -time.Second}); err == nil {
+		t.Error("expected error for negative interval")
+	}
+}
+
+func TestCheckpointer_EveryRecords(t *testing.T) {
+	store := &memCursorStore{}
+	cp, err := NewCheckpointer(store, CheckpointPolicy{EveryRecords: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i <= 3; i++ {
+		if err := cp.Ack(Record{SourceID: "s", NextOffset: int64(i * 10), Line: uint64(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if store.saves != 1 || store.saved.Offset != 20 {
+		t.Fatalf("after 3 acks: saves = %d, saved = %+v; want 1 save at offset 20", store.saves, store.saved)
+	}
+
+	if err := cp.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if store.saves != 2 || store.saved.Offset != 30 || store.saved.Line != 4 {
+		t.Fatalf("after flush: saves = %d, saved = %+v; want 2 saves at offset 30 line 4", store.saves, store.saved)
+	}
+
+	if err := cp.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if store.saves != 2 {
+		t.Fatalf("flush without new acks saved again: saves = %d", store.saves)
+	}
+}
+
+func TestFollow_CheckpointerResumesAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	path := writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt",
+		logLine("2024.01.01 00:00:01", "one")+
+			logLine("2024.01.01 00:00:02", "two"))
+
+	store := NewFileCursorStore(filepath.Join(t.TempDir(), "cursor.json"))
+	cp, err := NewCheckpointer(store, CheckpointPolicy{EveryRecords: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	records, errs := collectRecords(t, ctx, FollowConfig{
+		Directory:    dir,
+		PollInterval: testPollInterval,
+		Checkpointer: cp,
+	}, 2)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+
+	// collectRecords breaks out of the loop on the second record, so
+	// only the first one was acknowledged and flushed on return.
+	saved, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved == nil || *saved != records[0].Cursor() {
+		t.Fatalf("saved = %+v, want cursor after first record %+v", saved, records[0].Cursor())
+	}
+
+	appendToFile(t, path, logLine("2024.01.01 00:00:03", "three"))
+
+	cp2, err := NewCheckpointer(store, CheckpointPolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel2()
+
+	resumed, errs := collectRecords(t, ctx2, FollowConfig{
+		Directory:    dir,
+		PollInterval: testPollInterval,
+		Checkpointer: cp2,
+	}, 2)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if len(resumed) != 2 || resumed[0].Message != "two" || resumed[1].Message != "three" {
+		t.Fatalf("resumed = %+v, want records two and three", resumed)
+	}
+
+	saved, err = store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *saved != resumed[0].Cursor() {
+		t.Errorf("saved = %+v, want %+v", saved, resumed[0].Cursor())
+	}
+}
+
+func TestFollow_CheckpointSaveErrorStopsFollow(t *testing.T) {
+	dir := t.TempDir()
+	writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt",
+		logLine("2024.01.01 00:00:01", "one")+
+			logLine("2024.01.01 00:00:02", "two"))
+
+	saveErr := errors.New("disk full")
+	cp, err := NewCheckpointer(&memCursorStore{saveErr: saveErr}, CheckpointPolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	records, errs := collectRecords(t, ctx, FollowConfig{
+		Directory:    dir,
+		PollInterval: testPollInterval,
+		Checkpointer: cp,
+	}, 10)
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1 before the checkpoint failure", len(records))
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], saveErr) {
+		t.Fatalf("errs = %v, want wrapped save error", errs)
+	}
+}
+
+func TestFollow_CheckpointIntervalWhileIdle(t *testing.T) {
+	dir := t.TempDir()
+	writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt", logLine("2024.01.01 00:00:01", "one"))
+
+	store := NewFileCursorStore(filepath.Join(t.TempDir(), "cursor.json"))
+	cp, err := NewCheckpointer(store, CheckpointPolicy{Interval: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// The poll interval outlasts the test, so only the checkpoint
+	// interval can wake the idle follow.
+	got := make(chan Record, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for rec, err := range Follow(ctx, FollowConfig{Directory: dir, PollInterval: time.Minute, Checkpointer: cp}) {
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			got <- rec
+		}
+	}()
+
+	var rec Record
+	select {
+	case rec = <-got:
+	case <-ctx.Done():
+		t.Fatal("no record")
+	}
+	for {
+		saved, err := store.Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if saved != nil {
+			if *saved != rec.Cursor() {
+				t.Errorf("saved = %+v, want %+v", saved, rec.Cursor())
+			}
+			break
+		}
+		select {
+		case <-time.After(10 * time.Millisecond):
+		case <-ctx.Done():
+			t.Fatal("the acknowledged record was not saved while the log was idle")
+		}
+	}
+	cancel()
+	<-done
+}
@@ -0,0 +1,153 @@
+package vrclog
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// maxInvalidRanges bounds how many invalid byte ranges a
+// RecordIssueInvalidEncoding issue lists.
+const maxInvalidRanges = 16
+
+// Encoding is the character encoding of a log's bytes. Records always
+// hold UTF-8; offsets and RecordIDs refer to the bytes as stored.
+type Encoding uint8
+
+const (
+	EncodingUTF8 Encoding = iota
+	EncodingUTF16LE
+	EncodingUTF16BE
+)
+
+func (e Encoding) String() string {
+	switch e {
+	case EncodingUTF8:
+		return "UTF-8"
+	case EncodingUTF16LE:
+		return "UTF-16LE"
+	case EncodingUTF16BE:
+		return "UTF-16BE"
+	}
+	return "Encoding(" + strconv.Itoa(int(e)) + ")"
+}
+
+// byteOrderMarks lists the marks detectEncoding recognizes.
+var byteOrderMarks = []struct {
+	enc  Encoding
+	mark string
+}{
+	{EncodingUTF8, "\xef\xbb\xbf"},
+	{EncodingUTF16LE, "\xff\xfe"},
+	{EncodingUTF16BE, "\xfe\xff"},
+}
+
+// detectEncoding returns the encoding announced by the byte order mark
+// at the start of head, and the mark. ok is false if head has no mark.
+func detectEncoding(head []byte) (enc Encoding, bom string, ok bool) {
+	for _, m := range byteOrderMarks {
+		if strings.HasPrefix(string(head), m.mark) {
+			return m.enc, m.mark, true
+		}
+	}
+	return EncodingUTF8, "", false
+}
+
+// unitSize returns the size of a code unit of e in bytes.
+func (e Encoding) unitSize() int {
+	if e == EncodingUTF16LE || e == EncodingUTF16BE {
+		return 2
+	}
+	return 1
+}
+
+// stripTerminator removes a trailing LF or CRLF, encoded in e, from b.
+func (e Encoding) stripTerminator(b []byte) []byte {
+	var cr, lf string
+	switch e {
+	case EncodingUTF16LE:
+		cr, lf = "\r\x00", "\n\x00"
+	case EncodingUTF16BE:
+		cr, lf = "\x00\r", "\x00\n"
+	default:
+		return stripTerminator(b)
+	}
+	if !strings.HasSuffix(string(b), lf) {
+		return b
+	}
+	b = b[:len(b)-len(lf)]
+	if strings.HasSuffix(string(b), cr) {
+		b = b[:len(b)-len(cr)]
+	}
+	return b
+}
+
+// decodeRune decodes the first character of b. ok is false if b starts
+// with size bytes that are not valid in e.
+func (e Encoding) decodeRune(b []byte) (r rune, size int, ok bool) {
+	if e == EncodingUTF8 {
+		r, size = utf8.DecodeRune(b)
+		return r, size, r != utf8.RuneError || size > 1
+	}
+	if len(b) < 2 {
+		return utf8.RuneError, len(b), false
+	}
+	u := e.unit(b)
+	if !utf16.IsSurrogate(rune(u)) {
+		return rune(u), 2, true
+	}
+	if len(b) >= 4 {
+		if r := utf16.DecodeRune(rune(u), rune(e.unit(b[2:]))); r != utf8.RuneError {
+			return r, 4, true
+		}
+	}
+	return utf8.RuneError, 2, false
+}
+
+// unit returns the UTF-16 code unit at the start of b.
+func (e Encoding) unit(b []byte) uint16 {
+	if e == EncodingUTF16BE {
+		return uint16(b[0])<<8 | uint16(b[1])
+	}
+	return uint16(b[1])<<8 | uint16(b[0])
+}
+
+// decode converts b from e to UTF-8. Each maximal run of bytes that are
+// not valid in e becomes a single U+FFFD. invalid lists the first
+// maxInvalidRanges runs as ranges of b, and count is the number of
+// invalid bytes in all of them.
+func (e Encoding) decode(b []byte) (text []byte, invalid []ByteRange, count int64) {
+	if e == EncodingUTF8 && utf8.Valid(b) {
+		return b, nil, 0
+	}
+
+	text = make([]byte, 0, len(b))
+	runStart := -1
+	endRun := func(end int) {
+		if runStart < 0 {
+			return
+		}
+		text = utf8.AppendRune(text, utf8.RuneError)
+		if len(invalid) < maxInvalidRanges {
+			invalid = append(invalid, ByteRange{Start: int64(runStart), End: int64(end)})
+		}
+		count += int64(end - runStart)
+		runStart = -1
+	}
+	for i := 0; i < len(b); {
+		r, size, ok := e.decodeRune(b[i:])
+		if !ok {
+			if runStart < 0 {
+				runStart = i
+			}
+			i += size
+			continue
+		}
+		endRun(i)
+		text = utf8.AppendRune(text, r)
+		i += size
+	}
+	endRun(len(b))
+	return text, invalid, count
+}
@@ -0,0 +1,270 @@
+package vrclog
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"github.com/vrclog/vrclog-go/internal/logfile"
+)
+
+// encodeUTF16 encodes s as UTF-16 in the byte order of enc, without a
+// byte order mark.
+func encodeUTF16(s string, enc Encoding) []byte {
+	var b []byte
+	for _, u := range utf16.Encode([]rune(s)) {
+		if enc == EncodingUTF16BE {
+			b = binary.BigEndian.AppendUint16(b, u)
+		} else {
+			b = binary.LittleEndian.AppendUint16(b, u)
+		}
+	}
+	return b
+}
+
+func TestEncodingDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		enc     Encoding
+		in      []byte
+		want    string
+		invalid []ByteRange
+	}{
+		{"valid utf8", EncodingUTF8, []byte("héllo �"), "héllo �", nil},
+		{"utf8 runs", EncodingUTF8, []byte("a\xff\xfeb\xc3"), "a�b�", []ByteRange{{1, 3}, {4, 5}}},
+		{"utf16le pair", EncodingUTF16LE, encodeUTF16("a😀", EncodingUTF16LE), "a😀", nil},
+		{"utf16be", EncodingUTF16BE, encodeUTF16("ਅĊ", EncodingUTF16BE), "ਅĊ", nil},
+		{"lone surrogate", EncodingUTF16LE, []byte{'a', 0, 0x00, 0xd8, 'b', 0}, "a�b", []ByteRange{{2, 4}}},
+		{"odd byte", EncodingUTF16BE, []byte{0, 'a', 'b'}, "a�", []ByteRange{{2, 3}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, invalid, count := tt.enc.decode(tt.in)
+			if string(text) != tt.want {
+				t.Errorf("text = %q, want %q", text, tt.want)
+			}
+			if !reflect.DeepEqual(invalid, tt.invalid) {
+				t.Errorf("invalid = %v, want %v", invalid, tt.invalid)
+			}
+			var want int64
+			for _, r := range tt.invalid {
+				want += r.Len()
+			}
+			if count != want {
+				t.Errorf("count = %d, want %d", count, want)
+			}
+		})
+	}
+}
+
+func TestEncodingDecodeCapsRanges(t *testing.T) {
+	in := strings.Repeat("a\xff", maxInvalidRanges+4)
+	_, invalid, count := EncodingUTF8.decode([]byte(in))
+	if len(invalid) != maxInvalidRanges {
+		t.Errorf("got %d ranges, want %d", len(invalid), maxInvalidRanges)
+	}
+	if count != maxInvalidRanges+4 {
+		t.Errorf("count = %d, want %d", count, maxInvalidRanges+4)
+	}
+}
+
+func TestReadFile_InvalidUTF8Issue(t *testing.T) {
+	dir := t.TempDir()
+	first := "2024.01.01 00:00:01 Log        -  ok\n"
+	path := writeLog(t, dir, strings.TrimSuffix(first, "\n"), "2024.01.01 00:00:02 Log        -  bad \xff\xfe here \xc0")
+
+	records := readAll(t, ReadFileConfig{Path: path})
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	if records[0].Issue != nil {
+		t.Errorf("valid record has issue %+v", records[0].Issue)
+	}
+	rec := records[1]
+	if rec.Message != "bad � here �" {
+		t.Errorf("message = %q", rec.Message)
+	}
+	if rec.Time.IsZero() {
+		t.Error("header should still be decoded")
+	}
+	issue := rec.Issue
+	if issue == nil || issue.Code != RecordIssueInvalidEncoding {
+		t.Fatalf("issue = %+v, want invalid_encoding", issue)
+	}
+	bad := int64(len(first)) + int64(len("2024.01.01 00:00:02 Log        -  bad "))
+	want := []ByteRange{{Start: bad, End: bad + 2}, {Start: bad + 8, End: bad + 9}}
+	if !reflect.DeepEqual(issue.Invalid, want) || issue.InvalidBytes != 3 {
+		t.Errorf("invalid = %v (%d bytes), want %v (3 bytes)", issue.Invalid, issue.InvalidBytes, want)
+	}
+}
+
+func TestReadFile_UTF8BOM(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "output_log_2024-01-01_00-00-00.txt")
+	content := "\xef\xbb\xbf" + logLine("2024.01.01 00:00:01", "first") + logLine("2024.01.01 00:00:02", "second")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	records := readAll(t, ReadFileConfig{Path: path})
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	if records[0].Offset != 0 || records[0].Message != "first" || records[0].Level != LevelLog {
+		t.Errorf("first record = %+v, want the header decoded without the BOM", records[0])
+	}
+	if records[0].Issue != nil {
+		t.Errorf("issue = %+v", records[0].Issue)
+	}
+	if records[1].Offset != int64(3+len(logLine("2024.01.01 00:00:01", "first"))) {
+		t.Errorf("second offset = %d", records[1].Offset)
+	}
+}
+
+func TestReadFile_UTF16(t *testing.T) {
+	for _, tt := range []struct {
+		enc Encoding
+		bom string
+	}{
+		{EncodingUTF16LE, "\xff\xfe"},
+		{EncodingUTF16BE, "\xfe\xff"},
+	} {
+		t.Run(tt.enc.String(), func(t *testing.T) {
+			// Ċ (U+010A) and ਅ (U+0A05) contain a 0x0A byte that must not
+			// end a line.
+			lines := []string{
+				"2024.01.01 00:00:01 Log        -  Ċ first\r\n",
+				"2024.01.01 00:00:02 Warning    -  ਅ second\n",
+				"  continuation 😀\n",
+			}
+			// The first line includes the BOM.
+			content := []byte(tt.bom)
+			offsets := []int64{0}
+			for _, l := range lines {
+				content = append(content, encodeUTF16(l, tt.enc)...)
+				offsets = append(offsets, int64(len(content)))
+			}
+			dir := t.TempDir()
+			path := filepath.Join(dir, "output_log_2024-01-01_00-00-00.txt")
+			if err := os.WriteFile(path, content, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			records := readAll(t, ReadFileConfig{Path: path})
+			if len(records) != 3 {
+				t.Fatalf("got %d records, want 3: %+v", len(records), records)
+			}
+			wantMessages := []string{"Ċ first", "ਅ second", "  continuation 😀"}
+			for i, rec := range records {
+				if rec.Message != wantMessages[i] {
+					t.Errorf("record %d message = %q, want %q", i, rec.Message, wantMessages[i])
+				}
+				if rec.Issue != nil {
+					t.Errorf("record %d issue = %+v", i, rec.Issue)
+				}
+				if rec.Offset != offsets[i] || rec.Line != uint64(i+1) {
+					t.Errorf("record %d at %d line %d, want %d line %d", i, rec.Offset, rec.Line, offsets[i], i+1)
+				}
+			}
+			if records[1].Level != LevelWarning {
+				t.Errorf("level = %v, want warning", records[1].Level)
+			}
+			// IDs hash the bytes as stored, BOM included.
+			lineBytes := content[:offsets[1]]
+			if want := computeRecordID(records[0].SourceID, 0, sha256.Sum256(lineBytes)); records[0].ID != want {
+				t.Errorf("ID = %s, want hash of the stored bytes", records[0].ID)
+			}
+
+			// Resuming mid-file takes the encoding from the file's head.
+			resumed := readAll(t, ReadFileConfig{Path: path, Offset: records[1].Offset, Line: 2})
+			assertSameRecords(t, resumed, records[1:])
+
+			// Parallel reads fall back to sequential ones.
+			assertSameRecords(t, readAll(t, ReadFileConfig{Path: path, Parallelism: 4}), records)
+
+			// A stream starting mid-file needs the encoding spelled out.
+			tail := collectSeq(t, ReadRecords(context.Background(), bytes.NewReader(content[offsets[1]:]), ReaderConfig{
+				SourceID: records[0].SourceID,
+				Path:     path,
+				Offset:   offsets[1],
+				Line:     2,
+				Encoding: tt.enc,
+			}))
+			assertSameRecords(t, tail, records[1:])
+
+			if _, err := openReverseReader(ReverseReadConfig{Path: path}); err == nil {
+				t.Error("reverse read of a UTF-16 file should fail")
+			}
+		})
+	}
+}
+
+func TestReadFile_UTF16Oversize(t *testing.T) {
+	line := strings.Repeat("é", 40) + "\n"
+	content := append([]byte("\xff\xfe"), encodeUTF16(line, EncodingUTF16LE)...)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "output_log_2024-01-01_00-00-00.txt")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	records := readAll(t, ReadFileConfig{Path: path, Oversize: OversizeConfig{MaxLineSize: 21, Policy: OversizeHeadTail}})
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	// The head keeps 10 bytes (BOM and 4 units), the tail 10 (5 units).
+	if want := strings.Repeat("é", 9); records[0].Raw != want {
+		t.Errorf("raw = %q, want %q", records[0].Raw, want)
+	}
+	if records[0].Issue == nil || records[0].Issue.Code != RecordIssueLineTooLong {
+		t.Errorf("issue = %+v, want line_too_long", records[0].Issue)
+	}
+}
+
+func TestFollow_UTF16(t *testing.T) {
+	dir := t.TempDir()
+	name := "output_log_2024-01-01_00-00-00.txt"
+	first := "\xff\xfe" + string(encodeUTF16(logLine("2024.01.01 00:00:01", "first"), EncodingUTF16LE))
+	path := writeLogFile(t, dir, name, first)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	records, errs := collectRecords(t, ctx, FollowConfig{Directory: dir, PollInterval: testPollInterval}, 1)
+	if len(errs) != 0 || len(records) != 1 || records[0].Message != "first" {
+		t.Fatalf("records = %+v, errors = %v", records, errs)
+	}
+	cursor := records[0].Cursor()
+
+	appendToFile(t, path, string(encodeUTF16(logLine("2024.01.01 00:00:02", "Ċ second"), EncodingUTF16LE)))
+	records, errs = collectRecords(t, ctx, FollowConfig{Directory: dir, PollInterval: testPollInterval, Cursor: &cursor}, 1)
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if len(records) != 1 || records[0].Message != "Ċ second" || records[0].Offset != int64(len(first)) {
+		t.Fatalf("records = %+v, want \"Ċ second\" at %d", records, len(first))
+	}
+}
+
+func TestSnapshotBoundary_UTF16(t *testing.T) {
+	dir := t.TempDir()
+	first := encodeUTF16(logLine("2024.01.01 00:00:01", "Ċ"), EncodingUTF16LE)
+	content := append(append([]byte("\xff\xfe"), first...), encodeUTF16("partial", EncodingUTF16LE)...)
+	path := writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt", string(content))
+
+	off, line, err := snapshotBoundary(logfile.Root{}, path, int64(len(content)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if off != int64(2+len(first)) || line != 2 {
+		t.Errorf("boundary = %d line %d, want %d line 2", off, line, 2+len(first))
+	}
+}
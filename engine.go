@@ -7,6 +7,16 @@ import "fmt"
 // callers must serialize calls (e.g. via a single range-loop over iter.Seq2).
 type Engine struct {
 	adapters []Adapter
+	cfg      EngineConfig
+}
+
+// EngineConfig holds Engine settings other than its adapters.
+type EngineConfig struct {
+	// ProcessOversized runs adapters on records with a
+	// RecordIssueLineTooLong issue too, instead of only reporting the
+	// issue. Adapters then see the cut Raw and Message with Issue set,
+	// which is useful with OversizeHeadTail.
+	ProcessOversized bool
 }
 
 type Result struct {
@@ -15,6 +25,11 @@ type Result struct {
 }
 
 func NewEngine(adapters ...Adapter) (*Engine, error) {
+	return NewEngineWithConfig(EngineConfig{}, adapters...)
+}
+
+// NewEngineWithConfig is NewEngine with non-default settings.
+func NewEngineWithConfig(cfg EngineConfig, adapters ...Adapter) (*Engine, error) {
 	if len(adapters) == 0 {
 		return nil, ErrNoAdapters
 	}
@@ -37,7 +52,7 @@ func NewEngine(adapters ...Adapter) (*Engine, error) {
 		seen[id] = struct{}{}
 		copied[i] = a
 	}
-	return &Engine{adapters: copied}, nil
+	return &Engine{adapters: copied, cfg: cfg}, nil
 }
 
 // Process is NOT safe for concurrent calls from multiple goroutines;
@@ -57,7 +72,9 @@ func (e *Engine) Process(record Record) Result {
 			Message: record.Issue.Message,
 			Record:  ref,
 		})
-		return result
+		if record.Issue.affectsContent() && !(e.cfg.ProcessOversized && record.Issue.Code == RecordIssueLineTooLong) {
+			return result
+		}
 	}
 
 	for _, adapter := range e.adapters {
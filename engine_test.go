@@ -1,7 +1,9 @@
 package vrclog
 
 import (
+	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 )
@@ -234,6 +236,55 @@ func TestProcessRecordIssueSkipsAdapters(t *testing.T) {
 	}
 }
 
+func TestProcessTimeIssueStillRunsAdapters(t *testing.T) {
+	a := &mockAdapter{id: "time.issue", decode: func(Record) ([]Emission, error) {
+		return []Emission{validEmission()}, nil
+	}}
+	eng, _ := NewEngine(a)
+
+	rec := validRecord()
+	rec.Issue = &RecordIssue{Code: RecordIssueAmbiguousTime, Message: "local time occurs twice"}
+
+	result := eng.Process(rec)
+
+	if len(result.Observations) != 1 {
+		t.Errorf("got %d observations, want 1", len(result.Observations))
+	}
+	if len(result.Diagnostics) != 1 || result.Diagnostics[0].Code != DiagnosticRecordIssue {
+		t.Errorf("expected DiagnosticRecordIssue, got %+v", result.Diagnostics)
+	}
+}
+
+func TestProcessOversizedWithConfig(t *testing.T) {
+	var seen *RecordIssue
+	a := &mockAdapter{id: "oversized", decode: func(r Record) ([]Emission, error) {
+		seen = r.Issue
+		return []Emission{validEmission()}, nil
+	}}
+	rec := validRecord()
+	rec.Issue = &RecordIssue{Code: RecordIssueLineTooLong, Message: "line exceeds maximum size"}
+
+	eng, _ := NewEngine(a)
+	if result := eng.Process(rec); len(result.Observations) != 0 {
+		t.Fatalf("default engine should skip oversized records, got %d observations", len(result.Observations))
+	}
+
+	eng, err := NewEngineWithConfig(EngineConfig{ProcessOversized: true}, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := eng.Process(rec)
+	if len(result.Observations) != 1 {
+		t.Errorf("got %d observations, want 1", len(result.Observations))
+	}
+	if len(result.Diagnostics) != 1 || result.Diagnostics[0].Code != DiagnosticRecordIssue {
+		t.Errorf("expected DiagnosticRecordIssue, got %+v", result.Diagnostics)
+	}
+	if seen == nil || seen.Code != RecordIssueLineTooLong {
+		t.Errorf("adapter saw issue %+v, want line_too_long", seen)
+	}
+}
+
 func TestProcessObservationIDsDeterministic(t *testing.T) {
 	a := &mockAdapter{id: "det", decode: func(Record) ([]Emission, error) {
 		return []Emission{validEmission()}, nil
@@ -411,3 +462,24 @@ func TestProcessObservationFields(t *testing.T) {
 		t.Errorf("Record.Line = %d, want %d", obs.Record.Line, rec.Line)
 	}
 }
+
+func TestProcessInvalidEncodingStillRunsAdapters(t *testing.T) {
+	line := "2024.01.01 00:00:00 Log        -  [Behaviour] OnPlayerJoined Bad\xffName (usr_00000000-0000-0000-0000-000000000001)\n"
+	records := collectSeq(t, ReadRecords(context.Background(), strings.NewReader(line), ReaderConfig{SourceID: "src"}))
+	if len(records) != 1 || records[0].Issue == nil || records[0].Issue.Code != RecordIssueInvalidEncoding {
+		t.Fatalf("records = %+v, want one with an invalid_encoding issue", records)
+	}
+
+	eng, _ := NewEngine(NewVRChatAdapter())
+	result := eng.Process(records[0])
+	if len(result.Observations) != 1 {
+		t.Fatalf("got %d observations, want 1", len(result.Observations))
+	}
+	joined, ok := result.Observations[0].Event.(PlayerJoined)
+	if !ok || joined.Player.DisplayName != "Bad�Name" {
+		t.Errorf("event = %+v, want a join of Bad�Name", result.Observations[0].Event)
+	}
+	if len(result.Diagnostics) != 1 || result.Diagnostics[0].Code != DiagnosticRecordIssue {
+		t.Errorf("expected DiagnosticRecordIssue, got %+v", result.Diagnostics)
+	}
+}
@@ -17,7 +17,22 @@ var (
 	// ErrSourceTruncated is returned when an actively-followed log
 	// file's size drops below the offset Follow has already committed.
 	// This can happen if the file is truncated or replaced out from
-	// under Follow. Follow treats this as fatal: it does not rewind the
-	// cursor or silently restart from the beginning of the file.
+	// under Follow. By default Follow treats this as fatal: it does not
+	// rewind the cursor or silently restart from the beginning of the
+	// file unless FollowConfig.Truncation asks it to.
 	ErrSourceTruncated = errors.New("log source truncated")
+
+	// ErrCursorContentMismatch is returned when resuming from a cursor
+	// whose fingerprint no longer matches its file: the file at the
+	// cursor's path has been replaced or rewritten since the cursor was
+	// taken.
+	ErrCursorContentMismatch = errors.New("cursor content does not match source")
+
+	// ErrInvalidCursorToken is returned by ParseCursorToken for a string
+	// that is not a cursor token of a known version.
+	ErrInvalidCursorToken = errors.New("invalid cursor token")
+
+	// ErrInvalidSnapshot is returned when decoding a LogSnapshot from
+	// JSON that is not a snapshot of a known version.
+	ErrInvalidSnapshot = errors.New("invalid log snapshot")
 )
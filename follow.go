@@ -5,9 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"iter"
 	"os"
-	"path/filepath"
 	"time"
 
 	"github.com/vrclog/vrclog-go/internal/logfile"
@@ -22,24 +22,102 @@ const rotationSettlePolls = 2
 const rotationSettleTimeout = 2 * time.Second
 
 type FollowConfig struct {
-	Directory    string
+	Directory string
+	// FS, when set, is the file system Directory names a directory in;
+	// see ReadFileConfig.FS. An empty Directory is then the root of FS.
+	// Follow polls sizes through fs.StatFS, which FS must implement, and
+	// always uses FollowBackendPoll.
+	FS fs.FS
+
 	Cursor       *Cursor
 	PollInterval time.Duration
+	Backend      FollowBackend
+
+	// Checkpointer, when set, acknowledges consumed records and saves
+	// their cursor to its CursorStore. If Cursor is nil, Follow resumes
+	// from the cursor the store already holds.
+	Checkpointer *Checkpointer
+
+	// Location is the time zone header wall times are decoded in, as for
+	// ReadFileConfig.Location. Nil means time.Local.
+	Location *time.Location
+
+	// Header decodes line headers; see ReadFileConfig.Header.
+	Header HeaderDecoder
+
+	// OnSource, when set, receives lifecycle notifications about the
+	// files Follow reads. It is called on the goroutine ranging over
+	// Follow, in order with the records: a notification about a file
+	// arrives after every record read from it before that point and
+	// before any record read after it.
+	OnSource func(SourceNotification)
+
+	// Truncation selects what Follow does when the file it is reading
+	// shrinks below the committed offset. The default, TruncationFail,
+	// ends the follow with ErrSourceTruncated.
+	Truncation TruncationPolicy
+
+	// Snapshot, when set, starts Follow where the snapshot's coverage of
+	// the log directory ends instead of at the start of the newest file;
+	// see SnapshotMode. It is ignored when a Checkpointer supplies a
+	// saved cursor, and may not be combined with Cursor.
+	Snapshot     *LogSnapshot
+	SnapshotMode SnapshotMode
+
+	// Oversize selects how oversized lines are cut; see
+	// ReadFileConfig.Oversize.
+	Oversize OversizeConfig
+
+	// Sessions, when set, collects the SessionInfo of every file
+	// followed, as soon as its head is read.
+	Sessions *SessionExtractor
 }
 
+// TruncationPolicy selects how Follow recovers from a truncated file.
+// Every recovery sends a SourceTruncated notification to
+// FollowConfig.OnSource describing the bytes lost or read again.
+type TruncationPolicy uint8
+
+const (
+	// TruncationFail yields ErrSourceTruncated and stops.
+	TruncationFail TruncationPolicy = iota
+
+	// TruncationRewind reads the truncated file again from offset 0.
+	TruncationRewind
+
+	// TruncationSkipToNext stops reading the truncated file and moves on
+	// to the next log file once VRChat creates one. Nothing more is read
+	// from the truncated file, including anything written to it later.
+	TruncationSkipToNext
+)
+
+// DefaultLogDirectory returns the log directory ResolveLogDirectory
+// picks: $VRCLOG_DIR, then the user config file, then the current
+// user's VRChat log directory on Windows or, for VRChat run through
+// Steam Proton or Wine, on Linux.
 func DefaultLogDirectory() (string, error) {
-	dir, err := logfile.DefaultLogDirectory()
+	res, err := ResolveLogDirectory()
 	if err != nil {
-		if errors.Is(err, logfile.ErrNoLogDirectory) {
-			return "", ErrNoLogDirectory
-		}
 		return "", err
 	}
-	return dir, nil
+	return res.Dir, nil
 }
 
+// Follow tails the newest log file in a directory and keeps following
+// as VRChat rotates to new files. Use a FollowSession to also observe
+// its progress.
 func Follow(ctx context.Context, cfg FollowConfig) iter.Seq2[Record, error] {
+	return NewFollowSession(cfg).Records(ctx)
+}
+
+// Records follows the log directory exactly as Follow does. Range over
+// it from one goroutine at a time; each range starts a new follow and
+// resets the status.
+func (s *FollowSession) Records(ctx context.Context) iter.Seq2[Record, error] {
 	return func(yield func(Record, error) bool) {
+		cfg := s.cfg
+		s.update(func(st *FollowStatus) { *st = FollowStatus{} })
+
 		if cfg.PollInterval < 0 {
 			yield(Record{}, errors.New("poll interval must not be negative"))
 			return
@@ -49,57 +127,136 @@ func Follow(ctx context.Context, cfg FollowConfig) iter.Seq2[Record, error] {
 			return
 		}
 
+		if cfg.Backend > FollowBackendNotify {
+			yield(Record{}, fmt.Errorf("unknown follow backend %d", cfg.Backend))
+			return
+		}
+		if cfg.Truncation > TruncationSkipToNext {
+			yield(Record{}, fmt.Errorf("unknown truncation policy %d", cfg.Truncation))
+			return
+		}
+		if cfg.SnapshotMode > SnapshotReplay {
+			yield(Record{}, fmt.Errorf("unknown snapshot mode %d", cfg.SnapshotMode))
+			return
+		}
+		if err := cfg.Oversize.validate(); err != nil {
+			yield(Record{}, err)
+			return
+		}
+		if cfg.Cursor != nil && cfg.Snapshot != nil {
+			yield(Record{}, errors.New("cursor and snapshot are mutually exclusive"))
+			return
+		}
+
 		pollInterval := cfg.PollInterval
 		if pollInterval == 0 {
 			pollInterval = DefaultPollInterval
 		}
 
+		root := logfile.FSRoot(cfg.FS)
+		if !root.CanStat() {
+			yield(Record{}, errors.New("fs must implement fs.StatFS to be followed"))
+			return
+		}
+		backend := cfg.Backend
+		if !root.IsHost() {
+			backend = FollowBackendPoll
+		}
+
 		dir := cfg.Directory
-		if dir == "" {
+		if dir == "" && root.IsHost() {
 			d, err := DefaultLogDirectory()
 			if err != nil {
-				yield(Record{}, ErrNoLogDirectory)
+				yield(Record{}, err)
 				return
 			}
 			dir = d
 		}
-		absDir, err := filepath.Abs(dir)
+		dir, err := root.Clean(dir)
 		if err != nil {
 			yield(Record{}, err)
 			return
 		}
-		dir = filepath.Clean(absDir)
+
+		var checkpoint *checkpointRun
+		if cp := cfg.Checkpointer; cp != nil {
+			if cfg.Cursor == nil {
+				saved, err := cp.Load()
+				if err != nil {
+					yield(Record{}, fmt.Errorf("load checkpoint: %w", err))
+					return
+				}
+				cfg.Cursor = saved
+			}
+			checkpoint = &checkpointRun{cp: cp, next: yield}
+			yield = checkpoint.yield
+			defer checkpoint.finish()
+		}
+		yield = s.statusYield(yield)
+		if cfg.Cursor == nil && cfg.Snapshot != nil && cfg.SnapshotMode == SnapshotReplay {
+			yield = historicalYield(*cfg.Snapshot, yield)
+		}
+
+		// The waiter is set up before the first directory check so that
+		// any change after that check wakes the wait that follows it.
+		waiter := newChangeWaiter(backend, dir, pollInterval)
+		defer waiter.close()
+		if checkpoint != nil {
+			waiter = checkpointWaiter{changeWaiter: waiter, run: checkpoint}
+		}
 
 		fs := &followState{
+			root:         root,
 			dir:          dir,
 			pollInterval: pollInterval,
+			waiter:       waiter,
+			opts:         recordOptions{loc: cfg.Location, headers: cfg.Header, oversize: cfg.Oversize, sessions: cfg.Sessions},
+			session:      s,
+			onSource:     cfg.OnSource,
+			truncation:   cfg.Truncation,
 		}
 
-		if cfg.Cursor != nil {
+		switch {
+		case cfg.Cursor != nil:
 			fs.startWithCursor(ctx, cfg.Cursor, yield)
-		} else {
+		case cfg.Snapshot != nil:
+			fs.startWithSnapshot(ctx, *cfg.Snapshot, cfg.SnapshotMode, yield)
+		default:
 			fs.startWithoutCursor(ctx, yield)
 		}
 	}
 }
 
 type followState struct {
+	root         logfile.Root
 	dir          string
 	pollInterval time.Duration
+	waiter       changeWaiter
 	currentFile  string
+	currentSID   SourceID
 	currentOff   int64
 	currentLine  uint64
+	currentSize  int64
+	// abandoned is set when TruncationSkipToNext gave up on currentFile.
+	abandoned  bool
+	opts       recordOptions
+	session    *FollowSession
+	onSource   func(SourceNotification)
+	truncation TruncationPolicy
 }
 
 func (fs *followState) startWithCursor(ctx context.Context, cursor *Cursor, yield func(Record, error) bool) {
-	path, err := filepath.Abs(cursor.Path)
+	if cursor.Offset > 0 && cursor.Line == LineUnknown {
+		yield(Record{}, errors.New("cursor line is required when offset > 0"))
+		return
+	}
+	path, err := fs.root.Clean(cursor.Path)
 	if err != nil {
 		yield(Record{}, fmt.Errorf("cursor path: %w", err))
 		return
 	}
-	path = filepath.Clean(path)
 
-	srcID, err := logfile.SourceID(path)
+	srcID, err := fs.root.SourceID(path)
 	if err != nil {
 		yield(Record{}, fmt.Errorf("cursor source ID: %w", err))
 		return
@@ -110,7 +267,7 @@ func (fs *followState) startWithCursor(ctx context.Context, cursor *Cursor, yiel
 		return
 	}
 
-	f, info, err := logfile.OpenRegular(path)
+	f, info, err := fs.root.OpenRegular(path)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			yield(Record{}, ErrCursorSourceMissing)
@@ -126,6 +283,11 @@ func (fs *followState) startWithCursor(ctx context.Context, cursor *Cursor, yiel
 		return
 	}
 
+	if err := verifyCursorContent(io.NewSectionReader(f, 0, info.Size()), cursor); err != nil {
+		yield(Record{}, err)
+		return
+	}
+
 	if cursor.Offset > 0 {
 		if _, err := f.Seek(cursor.Offset, io.SeekStart); err != nil {
 			yield(Record{}, fmt.Errorf("seek cursor file: %w", err))
@@ -142,83 +304,106 @@ func (fs *followState) startWithCursor(ctx context.Context, cursor *Cursor, yiel
 	// candidate open failure is reported rather than silently defaulting
 	// to "cursor file is latest" (which would delay flushing a settled
 	// file's final fragment).
-	files, err := logfile.ListLogFilesStrict(fs.dir)
+	files, err := fs.root.ListLogFilesStrict(fs.dir)
 	if err != nil {
 		yield(Record{}, fmt.Errorf("list log files: %w", err))
 		return
 	}
 	isLatest := files[len(files)-1].Path == path
 
-	lr := newLineReader(f, cursor.Offset, cursor.Line)
+	fs.enterSource(path, sid, cursor.Offset, cursor.Line, info.Size())
+	if !fs.refreshHead(f, yield) {
+		return
+	}
+	if err := fs.opts.sessions.scanHead(ctx, sid, path, fs.opts, headAt(f)); err != nil {
+		yield(Record{}, err)
+		return
+	}
+
+	lr := newLineReader(f, cursor.Offset, cursor.Line, fs.opts)
 
 	var ok bool
 	if isLatest {
-		ok = readActiveRecords(ctx, lr, sid, path, yield)
+		ok = readActiveRecords(ctx, lr, sid, path, fs.opts, yield)
 	} else {
-		ok = readFiniteRecords(ctx, lr, sid, path, yield)
+		ok = readFiniteRecords(ctx, lr, sid, path, fs.opts, yield)
 	}
 	if !ok {
 		return
 	}
 
 	fs.currentFile = path
+	fs.currentSID = sid
 	fs.currentOff = lr.offset
 	fs.currentLine = lr.line
+	fs.setPending(lr.pending)
 
 	fs.pollLoop(ctx, yield)
 }
 
-func (fs *followState) startWithoutCursor(ctx context.Context, yield func(Record, error) bool) {
-	var latestPath string
-
+// waitForLogFiles lists the log directory, waiting until it holds at
+// least one log file. ok is false if ctx ended or an error was yielded.
+func (fs *followState) waitForLogFiles(ctx context.Context, yield func(Record, error) bool) (files []logfile.LogFileInfo, ok bool) {
 	for {
 		if ctx.Err() != nil {
-			return
+			return nil, false
 		}
 
-		files, err := logfile.ListLogFilesStrict(fs.dir)
+		files, err := fs.root.ListLogFilesStrict(fs.dir)
 		if err == nil {
-			latestPath = files[len(files)-1].Path
-			break
+			return files, true
 		}
 
 		if !errors.Is(err, logfile.ErrNoLogFiles) {
 			yield(Record{}, fmt.Errorf("list log files: %w", err))
-			return
+			return nil, false
 		}
 
-		select {
-		case <-time.After(fs.pollInterval):
-		case <-ctx.Done():
-			return
+		if !fs.waiter.wait(ctx) {
+			return nil, false
 		}
 	}
+}
 
-	f, _, err := logfile.OpenRegular(latestPath)
+func (fs *followState) startWithoutCursor(ctx context.Context, yield func(Record, error) bool) {
+	files, ok := fs.waitForLogFiles(ctx, yield)
+	if !ok {
+		return
+	}
+	latestPath := files[len(files)-1].Path
+
+	f, info, err := fs.root.OpenRegular(latestPath)
 	if err != nil {
 		yield(Record{}, err)
 		return
 	}
 
-	srcIDStr, err := logfile.SourceID(latestPath)
+	srcIDStr, err := fs.root.SourceID(latestPath)
 	if err != nil {
 		f.Close()
 		yield(Record{}, err)
 		return
 	}
 	sid := SourceID(srcIDStr)
-	lr := newLineReader(f, 0, 1)
+	fs.enterSource(latestPath, sid, 0, 1, info.Size())
+	if !fs.refreshHead(f, yield) {
+		f.Close()
+		return
+	}
+	lr := newLineReader(f, 0, 1, fs.opts)
 
 	// The latest file at startup is always the active file.
-	ok := readActiveRecords(ctx, lr, sid, latestPath, yield)
+	ok = readActiveRecords(ctx, lr, sid, latestPath, fs.opts, yield)
 	if !ok {
 		f.Close()
 		return
 	}
 
 	fs.currentFile = latestPath
+	fs.currentSID = sid
 	fs.currentOff = lr.offset
 	fs.currentLine = lr.line
+	fs.setPending(lr.pending)
 	f.Close()
 
 	fs.pollLoop(ctx, yield)
@@ -230,10 +415,14 @@ func (fs *followState) pollLoop(ctx context.Context, yield func(Record, error) b
 			return
 		}
 
-		status, err := checkFileStatus(fs.currentFile, fs.currentOff)
-		if err != nil {
-			yield(Record{}, err)
-			return
+		status := fileUnchanged
+		if !fs.abandoned {
+			var err error
+			status, err = fs.checkCurrent()
+			if err != nil {
+				yield(Record{}, err)
+				return
+			}
 		}
 
 		progressed := false
@@ -275,18 +464,25 @@ func (fs *followState) pollLoop(ctx context.Context, yield func(Record, error) b
 			continue
 		}
 
-		select {
-		case <-time.After(fs.pollInterval):
-		case <-ctx.Done():
+		if !fs.waiter.wait(ctx) {
 			return
 		}
 	}
 }
 
 func (fs *followState) settleAndSwitch(ctx context.Context, newerFiles []logfile.LogFileInfo, yield func(Record, error) bool) bool {
+	fs.notify(SourceNotification{
+		Kind:     SourceRotationDetected,
+		Path:     fs.currentFile,
+		SourceID: fs.currentSID,
+		Offset:   fs.currentOff,
+		Line:     fs.currentLine,
+		Next:     newerFiles[0].Path,
+	})
+
 	deadline := time.Now().Add(rotationSettleTimeout)
 
-	for i := 0; i < rotationSettlePolls; i++ {
+	for i := 0; i < rotationSettlePolls && !fs.abandoned; i++ {
 		if time.Now().After(deadline) {
 			break
 		}
@@ -294,7 +490,7 @@ func (fs *followState) settleAndSwitch(ctx context.Context, newerFiles []logfile
 			return false
 		}
 
-		status, err := checkFileStatus(fs.currentFile, fs.currentOff)
+		status, err := fs.checkCurrent()
 		if err != nil {
 			yield(Record{}, err)
 			return false
@@ -320,20 +516,23 @@ func (fs *followState) settleAndSwitch(ctx context.Context, newerFiles []logfile
 		}
 	}
 
-	status, err := checkFileStatus(fs.currentFile, fs.currentOff)
-	if err != nil {
-		yield(Record{}, err)
-		return false
-	}
-	if status == fileGrown {
-		if !fs.readGrowth(ctx, yield) {
+	if !fs.abandoned {
+		status, err := fs.checkCurrent()
+		if err != nil {
+			yield(Record{}, err)
 			return false
 		}
+		if status == fileGrown {
+			if !fs.readGrowth(ctx, yield) {
+				return false
+			}
+		}
 	}
 
 	// The old (now-settled) file may still hold an unterminated final
 	// fragment. Flush it exactly once before moving on to newer files.
-	if !fs.readFinalFlush(ctx, yield) {
+	// An abandoned file is not read again.
+	if !fs.abandoned && !fs.readFinalFlush(ctx, yield) {
 		return false
 	}
 
@@ -343,13 +542,13 @@ func (fs *followState) settleAndSwitch(ctx context.Context, newerFiles []logfile
 		}
 
 		isLast := i == len(newerFiles)-1
-		off, line, ok := readEntireFile(ctx, nf.Path, !isLast, yield)
-		if !ok {
+		fs.session.update(func(st *FollowStatus) { st.Rotations++ })
+		if !fs.readEntireFile(ctx, nf.Path, !isLast, yield) {
 			return false
 		}
-		fs.currentFile = nf.Path
-		fs.currentOff = off
-		fs.currentLine = line
+		if !isLast {
+			fs.notifyCurrent(SourceIntermediateRead)
+		}
 	}
 
 	return true
@@ -360,14 +559,14 @@ func (fs *followState) settleAndSwitch(ctx context.Context, newerFiles []logfile
 // settled (a newer file now exists) so its trailing fragment will never
 // receive a completing newline.
 func (fs *followState) readFinalFlush(ctx context.Context, yield func(Record, error) bool) bool {
-	f, _, err := logfile.OpenRegular(fs.currentFile)
+	f, _, err := fs.root.OpenRegular(fs.currentFile)
 	if err != nil {
 		yield(Record{}, err)
 		return false
 	}
 	defer f.Close()
 
-	srcIDStr, err := logfile.SourceID(fs.currentFile)
+	srcIDStr, err := fs.root.SourceID(fs.currentFile)
 	if err != nil {
 		yield(Record{}, err)
 		return false
@@ -381,12 +580,20 @@ func (fs *followState) readFinalFlush(ctx context.Context, yield func(Record, er
 		}
 	}
 
-	lr := newLineReader(f, fs.currentOff, fs.currentLine)
-	if !readFiniteRecords(ctx, lr, sid, fs.currentFile, yield) {
+	if !fs.refreshHead(f, yield) {
 		return false
 	}
+	lr := newLineReader(f, fs.currentOff, fs.currentLine, fs.opts)
+	if !readFiniteRecords(ctx, lr, sid, fs.currentFile, fs.opts, yield) {
+		return false
+	}
+	flushed := lr.offset != fs.currentOff
 	fs.currentOff = lr.offset
 	fs.currentLine = lr.line
+	if flushed {
+		fs.notifyCurrent(SourceFinalFragmentFlushed)
+	}
+	fs.notifyCurrent(SourceSettled)
 	return true
 }
 
@@ -395,14 +602,14 @@ func (fs *followState) readFinalFlush(ctx context.Context, yield func(Record, er
 // fragment is left unread and un-committed so a later poll can re-read
 // and (once it completes) emit it as a single Record.
 func (fs *followState) readGrowth(ctx context.Context, yield func(Record, error) bool) bool {
-	f, _, err := logfile.OpenRegular(fs.currentFile)
+	f, _, err := fs.root.OpenRegular(fs.currentFile)
 	if err != nil {
 		yield(Record{}, err)
 		return false
 	}
 	defer f.Close()
 
-	srcIDStr, err := logfile.SourceID(fs.currentFile)
+	srcIDStr, err := fs.root.SourceID(fs.currentFile)
 	if err != nil {
 		yield(Record{}, err)
 		return false
@@ -416,7 +623,11 @@ func (fs *followState) readGrowth(ctx context.Context, yield func(Record, error)
 		}
 	}
 
-	lr := newLineReader(f, fs.currentOff, fs.currentLine)
+	if !fs.refreshHead(f, yield) {
+		return false
+	}
+
+	lr := newLineReader(f, fs.currentOff, fs.currentLine, fs.opts)
 
 	for {
 		if ctx.Err() != nil {
@@ -425,6 +636,7 @@ func (fs *followState) readGrowth(ctx context.Context, yield func(Record, error)
 
 		rawBytes, rawHash, offset, nextOffset, lineNum, terminated, issue, readErr := lr.next()
 		if readErr == io.EOF {
+			fs.setPending(lr.pending)
 			return true
 		}
 		if readErr != nil {
@@ -439,7 +651,7 @@ func (fs *followState) readGrowth(ctx context.Context, yield func(Record, error)
 			continue
 		}
 
-		rec := buildRecord(rawBytes, rawHash, offset, nextOffset, lineNum, issue, sid, fs.currentFile)
+		rec := buildRecord(rawBytes, rawHash, offset, nextOffset, lineNum, issue, sid, fs.currentFile, fs.opts)
 
 		if !yield(rec, nil) {
 			return false
@@ -451,7 +663,7 @@ func (fs *followState) readGrowth(ctx context.Context, yield func(Record, error)
 }
 
 func (fs *followState) findNewerFiles() ([]logfile.LogFileInfo, error) {
-	files, err := logfile.ListLogFilesStrict(fs.dir)
+	files, err := fs.root.ListLogFilesStrict(fs.dir)
 	if err != nil {
 		if errors.Is(err, logfile.ErrNoLogFiles) {
 			return nil, nil
@@ -479,7 +691,7 @@ func (fs *followState) findNewerFiles() ([]logfile.LogFileInfo, error) {
 // this read (the caller is expected to track its own committed
 // offset/line separately, e.g. via followState.currentOff/currentLine,
 // and re-read the fragment later once more data arrives).
-func readActiveRecords(ctx context.Context, lr *lineReader, srcID SourceID, path string, yield func(Record, error) bool) bool {
+func readActiveRecords(ctx context.Context, lr *lineReader, srcID SourceID, path string, opts recordOptions, yield func(Record, error) bool) bool {
 	for {
 		if ctx.Err() != nil {
 			return false
@@ -497,7 +709,7 @@ func readActiveRecords(ctx context.Context, lr *lineReader, srcID SourceID, path
 			continue
 		}
 
-		rec := buildRecord(rawBytes, rawHash, offset, nextOffset, lineNum, issue, srcID, path)
+		rec := buildRecord(rawBytes, rawHash, offset, nextOffset, lineNum, issue, srcID, path, opts)
 
 		if !yield(rec, nil) {
 			return false
@@ -509,7 +721,7 @@ func readActiveRecords(ctx context.Context, lr *lineReader, srcID SourceID, path
 // unterminated final fragment. It is used for settled files: the current
 // (now-superseded) file after rotation, and intermediate files skipped
 // over during rotation.
-func readFiniteRecords(ctx context.Context, lr *lineReader, srcID SourceID, path string, yield func(Record, error) bool) bool {
+func readFiniteRecords(ctx context.Context, lr *lineReader, srcID SourceID, path string, opts recordOptions, yield func(Record, error) bool) bool {
 	for {
 		if ctx.Err() != nil {
 			return false
@@ -524,7 +736,7 @@ func readFiniteRecords(ctx context.Context, lr *lineReader, srcID SourceID, path
 			return false
 		}
 
-		rec := buildRecord(rawBytes, rawHash, offset, nextOffset, lineNum, issue, srcID, path)
+		rec := buildRecord(rawBytes, rawHash, offset, nextOffset, lineNum, issue, srcID, path, opts)
 
 		if !yield(rec, nil) {
 			return false
@@ -536,42 +748,68 @@ func readFiniteRecords(ctx context.Context, lr *lineReader, srcID SourceID, path
 			// the position manually so the caller's bookkeeping (and any
 			// subsequent read from this lr) is consistent.
 			lr.offset = nextOffset
-			lr.line = lineNum + 1
+			lr.line = nextLine(lineNum)
+			lr.pending = 0
 		}
 	}
 }
 
 // readEntireFile opens path fresh and reads it from the start using
-// either finite (flush=true) or active (flush=false) semantics.
+// either finite (flush=true) or active (flush=false) semantics, and on
+// success makes it the current file.
 //
 // ok is false whenever the caller must stop without yielding again: this
 // covers both a genuine read error (already reported via yield exactly
 // once, either here or inside readFiniteRecords/readActiveRecords) and
 // the consumer breaking out of the range loop (yield already returned
 // false once — calling it again would violate the iterator contract).
-func readEntireFile(ctx context.Context, path string, flush bool, yield func(Record, error) bool) (finalOff int64, finalLine uint64, ok bool) {
-	f, _, err := logfile.OpenRegular(path)
+func (fs *followState) readEntireFile(ctx context.Context, path string, flush bool, yield func(Record, error) bool) (ok bool) {
+	f, info, err := fs.root.OpenRegular(path)
 	if err != nil {
 		yield(Record{}, fmt.Errorf("open %s: %w", path, err))
-		return 0, 0, false
+		return false
 	}
 	defer f.Close()
 
-	srcIDStr, err := logfile.SourceID(path)
+	srcIDStr, err := fs.root.SourceID(path)
 	if err != nil {
 		yield(Record{}, fmt.Errorf("source ID for %s: %w", path, err))
-		return 0, 0, false
+		return false
 	}
 	sid := SourceID(srcIDStr)
-	lr := newLineReader(f, 0, 1)
+	fs.enterSource(path, sid, 0, 1, info.Size())
+	if !fs.refreshHead(f, yield) {
+		return false
+	}
+	lr := newLineReader(f, 0, 1, fs.opts)
 
-	var readOK bool
 	if flush {
-		readOK = readFiniteRecords(ctx, lr, sid, path, yield)
+		ok = readFiniteRecords(ctx, lr, sid, path, fs.opts, yield)
 	} else {
-		readOK = readActiveRecords(ctx, lr, sid, path, yield)
+		ok = readActiveRecords(ctx, lr, sid, path, fs.opts, yield)
+	}
+	if !ok {
+		return false
 	}
-	return lr.offset, lr.line, readOK
+	fs.currentFile = path
+	fs.currentSID = sid
+	fs.currentOff = lr.offset
+	fs.currentLine = lr.line
+	fs.abandoned = false
+	fs.setPending(lr.pending)
+	return true
+}
+
+// enterSource records that Follow starts reading path at off/line.
+func (fs *followState) enterSource(path string, sid SourceID, off int64, line uint64, size int64) {
+	fs.currentSize = size
+	fs.opts.head = nil
+	fs.session.update(func(st *FollowStatus) {
+		st.Path, st.SourceID = path, sid
+		st.Offset, st.Line = off, line
+		st.Size = size
+	})
+	fs.notify(SourceNotification{Kind: SourceOpened, Path: path, SourceID: sid, Offset: off, Line: line})
 }
 
 type fileStatus int
@@ -581,21 +819,95 @@ const (
 	fileGrown
 )
 
-// checkFileStatus stats path and compares its size against lastOffset.
+// checkFileStatus stats path in root and compares its size against lastOffset.
 // It returns ErrSourceTruncated (wrapped) if the file has shrunk below
 // the already-committed offset, and otherwise reports whether the file
 // has grown. Any stat error (missing file, permission denied, etc.) is
 // returned as-is so callers can distinguish it from "no new data".
-func checkFileStatus(path string, lastOffset int64) (fileStatus, error) {
-	info, err := os.Stat(path)
+func checkFileStatus(root logfile.Root, path string, lastOffset int64) (fileStatus, int64, error) {
+	info, err := root.Stat(path)
 	if err != nil {
-		return fileUnchanged, err
+		return fileUnchanged, 0, err
 	}
 	if info.Size() < lastOffset {
-		return fileUnchanged, fmt.Errorf("%w: file size %d < committed offset %d", ErrSourceTruncated, info.Size(), lastOffset)
+		return fileUnchanged, info.Size(), fmt.Errorf("%w: file size %d < committed offset %d", ErrSourceTruncated, info.Size(), lastOffset)
 	}
 	if info.Size() > lastOffset {
+		return fileGrown, info.Size(), nil
+	}
+	return fileUnchanged, info.Size(), nil
+}
+
+// checkCurrent runs checkFileStatus on the current file and records
+// the check in the session status. A truncation is returned as an error
+// under TruncationFail; otherwise it is recovered here and reported as
+// the status of the recovered position.
+func (fs *followState) checkCurrent() (fileStatus, error) {
+	status, size, err := checkFileStatus(fs.root, fs.currentFile, fs.currentOff)
+	if err != nil && (!errors.Is(err, ErrSourceTruncated) || fs.truncation == TruncationFail) {
+		return status, err
+	}
+	fs.session.update(func(st *FollowStatus) {
+		st.Size = size
+		st.LastPoll = time.Now()
+	})
+	lastSize := fs.currentSize
+	fs.currentSize = size
+	if err == nil {
+		return status, nil
+	}
+
+	info := TruncationInfo{Size: size}
+	if lastSize > fs.currentOff {
+		info.Lost = ByteRange{Start: fs.currentOff, End: lastSize}
+	}
+	if fs.truncation == TruncationRewind {
+		info.Reread = ByteRange{Start: 0, End: min(size, fs.currentOff)}
+	}
+	fs.notify(SourceNotification{
+		Kind:       SourceTruncated,
+		Path:       fs.currentFile,
+		SourceID:   fs.currentSID,
+		Offset:     fs.currentOff,
+		Line:       fs.currentLine,
+		Truncation: &info,
+	})
+
+	if fs.truncation == TruncationSkipToNext {
+		fs.abandoned = true
+		return fileUnchanged, nil
+	}
+	// The rewritten file has a new head, which cursors must fingerprint.
+	fs.currentOff, fs.currentLine = 0, 1
+	fs.opts.head = nil
+	fs.session.update(func(st *FollowStatus) {
+		st.Offset, st.Line = 0, 1
+		st.Pending = 0
+	})
+	if size > 0 {
 		return fileGrown, nil
 	}
 	return fileUnchanged, nil
 }
+
+// refreshHead re-reads the head of the file being read until it covers
+// cursorHeadSize bytes, so cursors of later records get a longer
+// fingerprint.
+func (fs *followState) refreshHead(f io.ReaderAt, yield func(Record, error) bool) bool {
+	if fs.opts.head.complete() {
+		return true
+	}
+	head, err := readSourceHead(f)
+	if err != nil {
+		yield(Record{}, err)
+		return false
+	}
+	fs.opts.head = head
+	return true
+}
+
+// setPending records the length of the unterminated fragment held back
+// at the current offset.
+func (fs *followState) setPending(n int64) {
+	fs.session.update(func(st *FollowStatus) { st.Pending = n })
+}
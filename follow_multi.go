@@ -0,0 +1,347 @@
+package vrclog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/vrclog/vrclog-go/internal/logfile"
+)
+
+// mergeWindowPolls is the default MergeWindow in poll intervals: long
+// enough for every other source to complete at least one poll.
+const mergeWindowPolls = 2
+
+// multiSourceQueueLimit bounds how many records a single source may
+// buffer ahead of the merge.
+const multiSourceQueueLimit = 256
+
+type MultiFollowConfig struct {
+	Directories []string
+
+	// Cursor, when set, is where each directory resumes, and FollowMulti
+	// advances it to each record just before yielding it, so that it can
+	// be saved from the loop body at any point. Set it to an empty
+	// MultiCursor to track a follow from the start.
+	Cursor *MultiCursor
+
+	PollInterval time.Duration
+	Backend      FollowBackend
+
+	// MergeWindow is how long a record waits for the other sources to
+	// produce something older before it is released anyway. While every
+	// source has a buffered record the merge is exact; once a source is
+	// quiet for longer than the window, a record it writes later with an
+	// older timestamp is emitted late rather than holding the stream.
+	// Zero uses two poll intervals.
+	MergeWindow time.Duration
+
+	// Location is the time zone header wall times are decoded in; see
+	// ReadFileConfig.Location. Records are merged by the decoded time.
+	Location *time.Location
+
+	// Header decodes line headers of every directory; see
+	// ReadFileConfig.Header.
+	Header HeaderDecoder
+
+	// Oversize selects how oversized lines are cut; see
+	// ReadFileConfig.Oversize.
+	Oversize OversizeConfig
+
+	// Sessions, when set, collects the SessionInfo of every file
+	// followed in any directory.
+	Sessions *SessionExtractor
+}
+
+// MultiCursor is the resume position of a merged multi-directory
+// follow: the cursor after the last emitted record of every source,
+// keyed by SourceID. A source that rotated away keeps its last position.
+// On resume each directory continues from the position of its newest
+// log file, as ordered by the time in the file name.
+type MultiCursor struct {
+	Positions map[SourceID]Cursor `json:"positions"`
+}
+
+// Advance records that rec has been emitted. FollowMulti calls it for
+// every record it yields.
+func (m *MultiCursor) Advance(rec Record) {
+	if m.Positions == nil {
+		m.Positions = make(map[SourceID]Cursor)
+	}
+	m.Positions[rec.SourceID] = rec.Cursor()
+}
+
+// cursorForDirectory returns the position of the newest log file in
+// dir, if any.
+func (m *MultiCursor) cursorForDirectory(dir string) (*Cursor, error) {
+	var found *Cursor
+	for _, c := range m.Positions {
+		p, err := filepath.Abs(c.Path)
+		if err != nil {
+			return nil, fmt.Errorf("cursor path: %w", err)
+		}
+		if filepath.Dir(filepath.Clean(p)) != dir {
+			continue
+		}
+		if found == nil || newerLogFile(c.Path, found.Path) {
+			c := c
+			found = &c
+		}
+	}
+	return found, nil
+}
+
+// newerLogFile reports whether the log file at a was created after the
+// one at b, going by the times in their names, or by the names if either
+// has none.
+func newerLogFile(a, b string) bool {
+	a, b = filepath.Base(a), filepath.Base(b)
+	ta, okA := logfile.FilenameTimestamp(a, time.UTC)
+	tb, okB := logfile.FilenameTimestamp(b, time.UTC)
+	if okA && okB && !ta.Equal(tb) {
+		return ta.After(tb)
+	}
+	return a > b
+}
+
+// FollowMulti follows several log directories at once and merges their
+// records into one stream ordered by header time. Records with equal
+// times are ordered by the position of their directory in Directories;
+// records of a single directory are never reordered, and a header-less
+// record sorts at the time of the record before it. Each directory is
+// followed exactly as Follow would, including rotation handling.
+func FollowMulti(ctx context.Context, cfg MultiFollowConfig) iter.Seq2[Record, error] {
+	return func(yield func(Record, error) bool) {
+		if len(cfg.Directories) == 0 {
+			yield(Record{}, errors.New("at least one directory is required"))
+			return
+		}
+		if cfg.PollInterval < 0 {
+			yield(Record{}, errors.New("poll interval must not be negative"))
+			return
+		}
+		if cfg.PollInterval > 0 && cfg.PollInterval < MinPollInterval {
+			yield(Record{}, fmt.Errorf("poll interval must be at least %s", MinPollInterval))
+			return
+		}
+		if cfg.MergeWindow < 0 {
+			yield(Record{}, errors.New("merge window must not be negative"))
+			return
+		}
+
+		pollInterval := cfg.PollInterval
+		if pollInterval == 0 {
+			pollInterval = DefaultPollInterval
+		}
+		window := cfg.MergeWindow
+		if window == 0 {
+			window = mergeWindowPolls * pollInterval
+		}
+
+		dirs := make([]string, len(cfg.Directories))
+		seen := make(map[string]struct{}, len(dirs))
+		for i, d := range cfg.Directories {
+			if d == "" {
+				yield(Record{}, errors.New("directory must not be empty"))
+				return
+			}
+			abs, err := filepath.Abs(d)
+			if err != nil {
+				yield(Record{}, err)
+				return
+			}
+			abs = filepath.Clean(abs)
+			if _, dup := seen[abs]; dup {
+				yield(Record{}, fmt.Errorf("duplicate directory %s", abs))
+				return
+			}
+			seen[abs] = struct{}{}
+			dirs[i] = abs
+		}
+
+		cursors := make([]*Cursor, len(dirs))
+		if cfg.Cursor != nil {
+			for i, d := range dirs {
+				c, err := cfg.Cursor.cursorForDirectory(d)
+				if err != nil {
+					yield(Record{}, err)
+					return
+				}
+				cursors[i] = c
+			}
+		}
+
+		sctx, cancel := context.WithCancel(ctx)
+		var wg sync.WaitGroup
+		defer func() {
+			cancel()
+			wg.Wait()
+		}()
+
+		m := &multiMerge{
+			items:  make(chan mergeItem),
+			queues: make([][]mergeItem, len(dirs)),
+			credit: make([]chan struct{}, len(dirs)),
+			last:   make([]time.Time, len(dirs)),
+			window: window,
+		}
+		for i, d := range dirs {
+			m.credit[i] = make(chan struct{}, multiSourceQueueLimit)
+			wg.Add(1)
+			go m.runSource(sctx, &wg, i, FollowConfig{
+				Directory:    d,
+				Cursor:       cursors[i],
+				PollInterval: cfg.PollInterval,
+				Backend:      cfg.Backend,
+				Location:     cfg.Location,
+				Header:       cfg.Header,
+				Oversize:     cfg.Oversize,
+				Sessions:     cfg.Sessions,
+			})
+		}
+
+		if cursor := cfg.Cursor; cursor != nil {
+			next := yield
+			yield = func(rec Record, err error) bool {
+				if err == nil {
+					cursor.Advance(rec)
+				}
+				return next(rec, err)
+			}
+		}
+		m.run(sctx, yield)
+	}
+}
+
+type mergeItem struct {
+	rec     Record
+	err     error
+	src     int
+	key     time.Time
+	arrived time.Time
+}
+
+type multiMerge struct {
+	items  chan mergeItem
+	queues [][]mergeItem
+	// credit[i] holds one token per record source i has buffered, so a
+	// fast source cannot run arbitrarily far ahead of a slow one.
+	credit []chan struct{}
+	// last is the most recent non-zero header time seen per source.
+	last   []time.Time
+	window time.Duration
+}
+
+func (m *multiMerge) runSource(ctx context.Context, wg *sync.WaitGroup, src int, cfg FollowConfig) {
+	defer wg.Done()
+	for rec, err := range Follow(ctx, cfg) {
+		select {
+		case m.credit[src] <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+		select {
+		case m.items <- mergeItem{rec: rec, err: err, src: src, arrived: time.Now()}:
+		case <-ctx.Done():
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (m *multiMerge) run(ctx context.Context, yield func(Record, error) bool) {
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if next, ok := m.ready(); ok {
+			item := m.pop(next)
+			if item.err != nil {
+				yield(Record{}, item.err)
+				return
+			}
+			if !yield(item.rec, nil) {
+				return
+			}
+			continue
+		}
+
+		var timeout <-chan time.Time
+		if src, ok := m.oldest(); ok {
+			wait := time.Until(m.queues[src][0].arrived.Add(m.window))
+			timeout = time.After(wait)
+		}
+
+		select {
+		case item := <-m.items:
+			m.push(item)
+		case <-timeout:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (m *multiMerge) push(item mergeItem) {
+	if item.err == nil {
+		if item.rec.Time.IsZero() {
+			item.key = m.last[item.src]
+		} else {
+			item.key = item.rec.Time
+			m.last[item.src] = item.rec.Time
+		}
+	}
+	m.queues[item.src] = append(m.queues[item.src], item)
+}
+
+func (m *multiMerge) pop(src int) mergeItem {
+	item := m.queues[src][0]
+	m.queues[src][0] = mergeItem{}
+	m.queues[src] = m.queues[src][1:]
+	<-m.credit[src]
+	return item
+}
+
+// oldest returns the source whose head record sorts first.
+func (m *multiMerge) oldest() (int, bool) {
+	best := -1
+	for i, q := range m.queues {
+		if len(q) == 0 {
+			continue
+		}
+		// Errors are delivered as soon as they reach the head.
+		if q[0].err != nil {
+			return i, true
+		}
+		if best < 0 || q[0].key.Before(m.queues[best][0].key) {
+			best = i
+		}
+	}
+	return best, best >= 0
+}
+
+// ready returns the source whose head may be emitted now: the oldest
+// head once every source has buffered something, or once it has waited
+// out the merge window.
+func (m *multiMerge) ready() (int, bool) {
+	src, ok := m.oldest()
+	if !ok {
+		return 0, false
+	}
+	if m.queues[src][0].err != nil {
+		return src, true
+	}
+	for _, q := range m.queues {
+		if len(q) == 0 {
+			return src, !time.Now().Before(m.queues[src][0].arrived.Add(m.window))
+		}
+	}
+	return src, true
+}
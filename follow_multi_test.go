@@ -0,0 +1,174 @@
+package vrclog
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func collectMulti(t *testing.T, cfg MultiFollowConfig, n int) []Record {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var records []Record
+	for rec, err := range FollowMulti(ctx, cfg) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		records = append(records, rec)
+		if len(records) >= n {
+			break
+		}
+	}
+	if len(records) != n {
+		t.Fatalf("got %d records, want %d", len(records), n)
+	}
+	return records
+}
+
+func messages(records []Record) []string {
+	out := make([]string, len(records))
+	for i, r := range records {
+		out[i] = r.Message
+	}
+	return out
+}
+
+func TestFollowMulti_MergesByHeaderTime(t *testing.T) {
+	a, b := t.TempDir(), t.TempDir()
+	writeLogFile(t, a, "output_log_2024-01-01_00-00-00.txt",
+		logLine("2024.01.01 00:00:01", "a1")+
+			logLine("2024.01.01 00:00:04", "a4"))
+	writeLogFile(t, b, "output_log_2024-01-01_00-00-00.txt",
+		logLine("2024.01.01 00:00:02", "b2")+
+			"continuation of b2\n"+
+			logLine("2024.01.01 00:00:03", "b3"))
+
+	records := collectMulti(t, MultiFollowConfig{
+		Directories:  []string{a, b},
+		PollInterval: testPollInterval,
+	}, 5)
+
+	got := strings.Join(messages(records), ",")
+	want := "a1,b2,continuation of b2,b3,a4"
+	if got != want {
+		t.Fatalf("merged order = %s, want %s", got, want)
+	}
+}
+
+func TestFollowMulti_EqualTimesOrderedByDirectory(t *testing.T) {
+	a, b := t.TempDir(), t.TempDir()
+	writeLogFile(t, a, "output_log_2024-01-01_00-00-00.txt",
+		logLine("2024.01.01 00:00:01", "a"))
+	writeLogFile(t, b, "output_log_2024-01-01_00-00-00.txt",
+		logLine("2024.01.01 00:00:01", "b"))
+
+	for _, tc := range []struct {
+		dirs []string
+		want string
+	}{
+		{[]string{a, b}, "a,b"},
+		{[]string{b, a}, "b,a"},
+	} {
+		records := collectMulti(t, MultiFollowConfig{Directories: tc.dirs, PollInterval: testPollInterval}, 2)
+		if got := strings.Join(messages(records), ","); got != tc.want {
+			t.Errorf("order = %s, want %s", got, tc.want)
+		}
+	}
+}
+
+func TestFollowMulti_QuietSourceDoesNotBlock(t *testing.T) {
+	a, b := t.TempDir(), t.TempDir()
+	writeLogFile(t, a, "output_log_2024-01-01_00-00-00.txt",
+		logLine("2024.01.01 00:00:01", "only"))
+
+	start := time.Now()
+	records := collectMulti(t, MultiFollowConfig{
+		Directories:  []string{a, b},
+		PollInterval: testPollInterval,
+		MergeWindow:  200 * time.Millisecond,
+	}, 1)
+	if records[0].Message != "only" {
+		t.Fatalf("Message = %q, want %q", records[0].Message, "only")
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Fatalf("record released after %s, want about one merge window", elapsed)
+	}
+}
+
+func TestFollowMulti_ResumeFromMultiCursor(t *testing.T) {
+	a, b := t.TempDir(), t.TempDir()
+	writeLogFile(t, a, "output_log_2024-01-01_00-00-00.txt",
+		logLine("2024.01.01 00:00:01", "a1")+
+			logLine("2024.01.01 00:00:03", "a3"))
+	writeLogFile(t, b, "output_log_2024-01-01_00-00-00.txt",
+		logLine("2024.01.01 00:00:02", "b2")+
+			logLine("2024.01.01 00:00:04", "b4"))
+
+	// FollowMulti advances the cursor itself.
+	cursor := &MultiCursor{}
+	cfg := MultiFollowConfig{Directories: []string{a, b}, PollInterval: testPollInterval, Cursor: cursor}
+	first := collectMulti(t, cfg, 2)
+	if len(cursor.Positions) != 2 {
+		t.Fatalf("cursor has %d positions, want 2", len(cursor.Positions))
+	}
+	for _, rec := range first {
+		if cursor.Positions[rec.SourceID] != rec.Cursor() {
+			t.Errorf("position of %s = %+v, want %+v", rec.Message, cursor.Positions[rec.SourceID], rec.Cursor())
+		}
+	}
+
+	rest := collectMulti(t, cfg, 2)
+	if got := strings.Join(messages(rest), ","); got != "a3,b4" {
+		t.Fatalf("resumed order = %s, want a3,b4", got)
+	}
+}
+
+func TestMultiCursor_OnePositionPerSource(t *testing.T) {
+	dir, other := t.TempDir(), t.TempDir()
+	var c MultiCursor
+	c.Advance(Record{SourceID: "old", Path: filepath.Join(dir, "output_log_2024-01-02_00-00-00.txt"), NextOffset: 10, Line: 1})
+	c.Advance(Record{SourceID: "other", Path: filepath.Join(other, "output_log_2024-01-01_00-00-00.txt"), NextOffset: 5, Line: 1})
+	c.Advance(Record{SourceID: "new", Path: filepath.Join(dir, "output_log_2024-01-10_00-00-00.txt"), NextOffset: 7, Line: 1})
+	c.Advance(Record{SourceID: "old", Path: filepath.Join(dir, "output_log_2024-01-02_00-00-00.txt"), NextOffset: 20, Line: 2})
+
+	if len(c.Positions) != 3 || c.Positions["old"].Offset != 20 {
+		t.Fatalf("positions = %+v, want the latest of each of three sources", c.Positions)
+	}
+	got, err := c.cursorForDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || got.Offset != 7 {
+		t.Errorf("directory resumes from %+v, want the newest file's position", got)
+	}
+}
+
+func TestFollowMulti_PollIntervalBelowMinimum(t *testing.T) {
+	var gotErr error
+	for _, err := range FollowMulti(context.Background(), MultiFollowConfig{
+		Directories:  []string{t.TempDir()},
+		PollInterval: MinPollInterval / 2,
+	}) {
+		gotErr = err
+		break
+	}
+	if gotErr == nil || !strings.Contains(gotErr.Error(), "poll interval must be at least") {
+		t.Fatalf("expected minimum poll interval error, got %v", gotErr)
+	}
+}
+
+func TestFollowMulti_RejectsDuplicateDirectories(t *testing.T) {
+	dir := t.TempDir()
+	var gotErr error
+	for _, err := range FollowMulti(context.Background(), MultiFollowConfig{Directories: []string{dir, dir + "/."}}) {
+		gotErr = err
+		break
+	}
+	if gotErr == nil || !strings.Contains(gotErr.Error(), "duplicate directory") {
+		t.Fatalf("expected duplicate directory error, got %v", gotErr)
+	}
+}
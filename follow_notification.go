@@ -0,0 +1,95 @@
+package vrclog
+
+// SourceNotificationKind identifies a point in a followed file's
+// lifecycle.
+type SourceNotificationKind string
+
+const (
+	// SourceOpened reports that Follow started reading Path at
+	// Offset/Line, at startup or after a rotation.
+	SourceOpened SourceNotificationKind = "source_opened"
+
+	// SourceRotationDetected reports that a newer log file, Next, has
+	// appeared. Follow now reads what is left of Path and settles it.
+	SourceRotationDetected SourceNotificationKind = "rotation_detected"
+
+	// SourceFinalFragmentFlushed reports that the unterminated final
+	// fragment of a settling file was emitted as a record ending at
+	// Offset, without ever receiving its newline.
+	SourceFinalFragmentFlushed SourceNotificationKind = "final_fragment_flushed"
+
+	// SourceSettled reports that Follow has read Path to its end at
+	// Offset/Line and will not read it again.
+	SourceSettled SourceNotificationKind = "source_settled"
+
+	// SourceIntermediateRead reports that a file which was already
+	// superseded when Follow reached it has been read in full and
+	// settled. It replaces SourceSettled for such files.
+	SourceIntermediateRead SourceNotificationKind = "intermediate_read"
+
+	// SourceTruncated reports that Path shrank below the committed
+	// Offset/Line and was recovered according to
+	// FollowConfig.Truncation. Truncation describes the affected bytes.
+	SourceTruncated SourceNotificationKind = "source_truncated"
+)
+
+// ByteRange is the half-open byte range [Start, End) of a file.
+type ByteRange struct {
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+}
+
+// Len returns the number of bytes in r.
+func (r ByteRange) Len() int64 {
+	return r.End - r.Start
+}
+
+// TruncationInfo describes a recovered truncation.
+type TruncationInfo struct {
+	// Size is the file size after the truncation.
+	Size int64 `json:"size"`
+
+	// Lost is the range of the file, as Follow last observed it, that
+	// had not been yielded yet and can no longer be read. It is empty
+	// when Follow had caught up.
+	Lost ByteRange `json:"lost"`
+
+	// Reread is the range of the file Follow had already yielded and
+	// now reads again from the start under TruncationRewind,
+	// [0, min(Size, committed offset)). Records from it are yielded
+	// again, with the same IDs if their bytes are unchanged; bytes past
+	// it are new. It is empty under TruncationSkipToNext.
+	Reread ByteRange `json:"reread"`
+}
+
+// SourceNotification is a lifecycle notification from Follow. Offset and
+// Line are the committed position in Path when it was sent.
+type SourceNotification struct {
+	Kind     SourceNotificationKind `json:"kind"`
+	Path     string                 `json:"path"`
+	SourceID SourceID               `json:"source_id"`
+	Offset   int64                  `json:"offset"`
+	Line     uint64                 `json:"line"`
+	Next     string                 `json:"next,omitempty"`
+
+	// Truncation is set on SourceTruncated notifications.
+	Truncation *TruncationInfo `json:"truncation,omitempty"`
+}
+
+// notify sends n to the configured callback, if any.
+func (fs *followState) notify(n SourceNotification) {
+	if fs.onSource != nil {
+		fs.onSource(n)
+	}
+}
+
+// notifyCurrent sends a notification of kind about the current file.
+func (fs *followState) notifyCurrent(kind SourceNotificationKind) {
+	fs.notify(SourceNotification{
+		Kind:     kind,
+		Path:     fs.currentFile,
+		SourceID: fs.currentSID,
+		Offset:   fs.currentOff,
+		Line:     fs.currentLine,
+	})
+}
@@ -0,0 +1,103 @@
+package vrclog
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFollow_SourceNotifications(t *testing.T) {
+	dir := t.TempDir()
+	pathA := writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt",
+		logLine("2024.01.01 00:00:00", "a1")+"2024.01.01 00:00:01 Log        -  a2")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var events []string
+	var notes []SourceNotification
+	cfg := FollowConfig{
+		Directory:    dir,
+		PollInterval: testPollInterval,
+		OnSource: func(n SourceNotification) {
+			events = append(events, string(n.Kind)+" "+filepath.Base(n.Path))
+			notes = append(notes, n)
+		},
+	}
+
+	rotated := false
+	for rec, err := range Follow(ctx, cfg) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		events = append(events, "record "+rec.Message)
+		if !rotated {
+			rotated = true
+			writeLogFile(t, dir, "output_log_2024-01-01_01-00-00.txt", logLine("2024.01.01 01:00:00", "b1"))
+			writeLogFile(t, dir, "output_log_2024-01-01_02-00-00.txt", logLine("2024.01.01 02:00:00", "c1"))
+		}
+		if rec.Message == "c1" {
+			break
+		}
+	}
+
+	want := []string{
+		"source_opened output_log_2024-01-01_00-00-00.txt",
+		"record a1",
+		"rotation_detected output_log_2024-01-01_00-00-00.txt",
+		"record a2",
+		"final_fragment_flushed output_log_2024-01-01_00-00-00.txt",
+		"source_settled output_log_2024-01-01_00-00-00.txt",
+		"source_opened output_log_2024-01-01_01-00-00.txt",
+		"record b1",
+		"intermediate_read output_log_2024-01-01_01-00-00.txt",
+		"source_opened output_log_2024-01-01_02-00-00.txt",
+		"record c1",
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("events:\n%q\nwant:\n%q", events, want)
+	}
+
+	if notes[0].Offset != 0 || notes[0].Line != 1 || notes[0].SourceID == "" {
+		t.Errorf("source_opened = %+v, want offset 0 line 1 with a SourceID", notes[0])
+	}
+	rot := notes[1]
+	if rot.Path != pathA || rot.Next != filepath.Join(dir, "output_log_2024-01-01_01-00-00.txt") {
+		t.Errorf("rotation_detected = %+v", rot)
+	}
+	settled := notes[3]
+	if settled.Offset != int64(len(logLine("2024.01.01 00:00:00", "a1")+"2024.01.01 00:00:01 Log        -  a2")) || settled.Line != 3 {
+		t.Errorf("source_settled = %+v, want end of file at line 3", settled)
+	}
+}
+
+func TestFollow_SourceNotificationsWithCursor(t *testing.T) {
+	dir := t.TempDir()
+	line1 := logLine("2024.01.01 00:00:00", "first")
+	line2 := logLine("2024.01.01 00:00:01", "second")
+	writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt", line1+line2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	first, _ := collectRecords(t, ctx, FollowConfig{Directory: dir, PollInterval: testPollInterval}, 1)
+	cursor := first[0].Cursor()
+
+	var notes []SourceNotification
+	cfg := FollowConfig{
+		Directory:    dir,
+		PollInterval: testPollInterval,
+		Cursor:       &cursor,
+		OnSource:     func(n SourceNotification) { notes = append(notes, n) },
+	}
+	collectRecords(t, ctx, cfg, 1)
+
+	if len(notes) != 1 || notes[0].Kind != SourceOpened {
+		t.Fatalf("notifications = %+v, want one source_opened", notes)
+	}
+	if notes[0].Offset != int64(len(line1)) || notes[0].Line != 2 {
+		t.Errorf("source_opened at %d/%d, want %d/2", notes[0].Offset, notes[0].Line, len(line1))
+	}
+}
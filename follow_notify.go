@@ -0,0 +1,63 @@
+package vrclog
+
+import (
+	"context"
+	"time"
+)
+
+// FollowBackend selects how Follow learns that a log file has changed.
+type FollowBackend uint8
+
+const (
+	// FollowBackendPoll re-checks the log directory every PollInterval.
+	FollowBackendPoll FollowBackend = iota
+
+	// FollowBackendNotify waits for filesystem change notifications
+	// (inotify on Linux) and re-checks as soon as a log file is written
+	// or created. PollInterval still paces rotation settling, and a
+	// full re-check still happens every notifyRescanInterval in case a
+	// notification is missed. Where notifications are unavailable
+	// (other operating systems, inotify limits reached, unsupported
+	// filesystems), or stop because the directory was removed or
+	// moved, Follow silently falls back to FollowBackendPoll.
+	FollowBackendNotify
+)
+
+// notifyRescanInterval bounds how long the notify backend sleeps
+// without any notification before re-checking anyway.
+const notifyRescanInterval = 30 * time.Second
+
+// changeWaiter blocks Follow between checks of the log directory.
+type changeWaiter interface {
+	// wait returns when a change may have happened, when the fallback
+	// interval elapses, or when ctx is done. It returns false only if
+	// ctx is done.
+	wait(ctx context.Context) bool
+	close()
+}
+
+type pollWaiter struct {
+	interval time.Duration
+}
+
+func (w pollWaiter) wait(ctx context.Context) bool {
+	select {
+	case <-time.After(w.interval):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (pollWaiter) close() {}
+
+// newChangeWaiter returns a waiter for the selected backend. A notify
+// backend that cannot be set up for dir degrades to polling.
+func newChangeWaiter(backend FollowBackend, dir string, pollInterval time.Duration) changeWaiter {
+	if backend == FollowBackendNotify {
+		if w, err := newNotifyWaiter(dir, pollInterval); err == nil {
+			return w
+		}
+	}
+	return pollWaiter{interval: pollInterval}
+}
@@ -0,0 +1,133 @@
+//go:build linux
+
+package vrclog
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+const inotifyMask = syscall.IN_MODIFY | syscall.IN_CLOSE_WRITE | syscall.IN_CREATE |
+	syscall.IN_MOVED_TO | syscall.IN_MOVED_FROM | syscall.IN_DELETE | syscall.IN_ATTRIB |
+	syscall.IN_DELETE_SELF | syscall.IN_MOVE_SELF
+
+// inotifyWaiter wakes Follow whenever an output_log file in the
+// watched directory is written, created, moved or removed. Once the
+// watch is gone, because the directory was removed or moved or reading
+// events failed, it is dead and waits like fallback instead.
+type inotifyWaiter struct {
+	f        *os.File
+	fallback pollWaiter
+	changed  chan struct{}
+	dead     chan struct{}
+	done     chan struct{}
+}
+
+func newNotifyWaiter(dir string, pollInterval time.Duration) (changeWaiter, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_NONBLOCK | syscall.IN_CLOEXEC)
+	if err != nil {
+		return nil, os.NewSyscallError("inotify_init1", err)
+	}
+	if _, err := syscall.InotifyAddWatch(fd, dir, inotifyMask); err != nil {
+		syscall.Close(fd)
+		return nil, os.NewSyscallError("inotify_add_watch", err)
+	}
+
+	// The descriptor is non-blocking, so os.NewFile registers it with
+	// the runtime poller: Read parks the goroutine instead of a thread,
+	// and Close unblocks a pending Read.
+	w := &inotifyWaiter{
+		f:        os.NewFile(uintptr(fd), "inotify"),
+		fallback: pollWaiter{interval: pollInterval},
+		changed:  make(chan struct{}, 1),
+		dead:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go w.readLoop()
+	return w, nil
+}
+
+func (w *inotifyWaiter) readLoop() {
+	defer close(w.done)
+	// Closed, broken or unwatched: no notification will come again, so
+	// waits poll from now on.
+	defer close(w.dead)
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := w.f.Read(buf)
+		if err != nil {
+			return
+		}
+		relevant, gone := inotifyScan(buf[:n])
+		if gone {
+			return
+		}
+		if relevant {
+			w.signal()
+		}
+	}
+}
+
+func (w *inotifyWaiter) signal() {
+	select {
+	case w.changed <- struct{}{}:
+	default:
+	}
+}
+
+func (w *inotifyWaiter) wait(ctx context.Context) bool {
+	select {
+	case <-w.dead:
+		return w.fallback.wait(ctx)
+	default:
+	}
+	select {
+	case <-w.changed:
+		return true
+	case <-w.dead:
+		return true
+	case <-time.After(notifyRescanInterval):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (w *inotifyWaiter) close() {
+	w.f.Close()
+	<-w.done
+}
+
+// inotifyScan reports whether any event in buf concerns an output_log
+// file or the watched directory itself, and whether the watch is gone:
+// removed by the kernel (IN_IGNORED, which follows IN_DELETE_SELF), or
+// left on a directory that moved away from the followed path.
+func inotifyScan(buf []byte) (relevant, gone bool) {
+	for len(buf) >= syscall.SizeofInotifyEvent {
+		ev := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[0]))
+		end := syscall.SizeofInotifyEvent + int(ev.Len)
+		if end > len(buf) {
+			return true, false
+		}
+		if ev.Mask&(syscall.IN_IGNORED|syscall.IN_DELETE_SELF|syscall.IN_MOVE_SELF) != 0 {
+			return true, true
+		}
+		if ev.Mask&syscall.IN_Q_OVERFLOW != 0 {
+			relevant = true
+		}
+		name := buf[syscall.SizeofInotifyEvent:end]
+		if i := bytes.IndexByte(name, 0); i >= 0 {
+			name = name[:i]
+		}
+		if matched, _ := filepath.Match("output_log_*.txt", string(name)); matched {
+			relevant = true
+		}
+		buf = buf[end:]
+	}
+	return relevant, false
+}
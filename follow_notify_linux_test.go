@@ -0,0 +1,49 @@
+//go:build linux
+
+package vrclog
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestInotifyWaiter_PollsOnceWatchIsRemoved(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	w := newChangeWaiter(FollowBackendNotify, dir, testPollInterval)
+	defer w.close()
+	iw, ok := w.(*inotifyWaiter)
+	if !ok {
+		t.Skipf("inotify is unavailable, got %T", w)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Removing the directory removes its watch, which wakes the waiter
+	// once and leaves it dead.
+	if err := os.Remove(dir); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-iw.dead:
+	case <-ctx.Done():
+		t.Fatal("waiter not dead after its watch was removed")
+	}
+
+	// From then on waits last a poll interval, not notifyRescanInterval.
+	for range 3 {
+		start := time.Now()
+		if !w.wait(ctx) {
+			t.Fatal("wait reported ctx done")
+		}
+		if waited := time.Since(start); waited >= time.Second {
+			t.Fatalf("wait took %s, want about the poll interval", waited)
+		}
+	}
+}
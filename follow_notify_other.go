@@ -0,0 +1,12 @@
+//go:build !linux
+
+package vrclog
+
+import (
+	"errors"
+	"time"
+)
+
+func newNotifyWaiter(dir string, pollInterval time.Duration) (changeWaiter, error) {
+	return nil, errors.New("change notifications are not supported on this platform")
+}
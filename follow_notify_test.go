@@ -0,0 +1,128 @@
+package vrclog
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFollow_UnknownBackend(t *testing.T) {
+	var gotErr error
+	for _, err := range Follow(context.Background(), FollowConfig{
+		Directory: t.TempDir(),
+		Backend:   FollowBackendNotify + 1,
+	}) {
+		gotErr = err
+		break
+	}
+	if gotErr == nil || !strings.Contains(gotErr.Error(), "unknown follow backend") {
+		t.Fatalf("expected unknown backend error, got %v", gotErr)
+	}
+}
+
+func TestNewChangeWaiter_FallsBackToPolling(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "does-not-exist")
+
+	w := newChangeWaiter(FollowBackendNotify, missing, testPollInterval)
+	defer w.close()
+
+	if _, ok := w.(pollWaiter); !ok {
+		t.Fatalf("expected pollWaiter fallback for missing directory, got %T", w)
+	}
+}
+
+func TestFollow_NotifyBackendDeliversAppendBeforePollInterval(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("change notifications are only implemented on linux")
+	}
+	dir := t.TempDir()
+	path := writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt",
+		logLine("2024.01.01 00:00:01", "existing"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	records := make(chan Record, 4)
+	go func() {
+		defer close(records)
+		for rec, err := range Follow(ctx, FollowConfig{
+			Directory:    dir,
+			PollInterval: 5 * time.Second,
+			Backend:      FollowBackendNotify,
+		}) {
+			if err != nil {
+				return
+			}
+			records <- rec
+		}
+	}()
+
+	first := <-records
+	if first.Message != "existing" {
+		t.Fatalf("first.Message = %q, want %q", first.Message, "existing")
+	}
+
+	// Give Follow time to enter its wait before appending.
+	time.Sleep(200 * time.Millisecond)
+	appended := time.Now()
+	appendToFile(t, path, logLine("2024.01.01 00:00:02", "appended"))
+
+	select {
+	case rec := <-records:
+		if rec.Message != "appended" {
+			t.Fatalf("rec.Message = %q, want %q", rec.Message, "appended")
+		}
+		if waited := time.Since(appended); waited >= 5*time.Second {
+			t.Fatalf("append delivered after %s, expected notification before poll interval", waited)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("notify backend did not deliver appended record before poll interval")
+	}
+}
+
+func TestFollow_NotifyBackendDetectsRotation(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("change notifications are only implemented on linux")
+	}
+	dir := t.TempDir()
+	writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt",
+		logLine("2024.01.01 00:00:01", "old"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	records := make(chan Record, 4)
+	go func() {
+		defer close(records)
+		for rec, err := range Follow(ctx, FollowConfig{
+			Directory:    dir,
+			PollInterval: testPollInterval,
+			Backend:      FollowBackendNotify,
+		}) {
+			if err != nil {
+				return
+			}
+			records <- rec
+		}
+	}()
+
+	if rec := <-records; rec.Message != "old" {
+		t.Fatalf("rec.Message = %q, want %q", rec.Message, "old")
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	writeLogFile(t, dir, "output_log_2024-01-01_01-00-00.txt",
+		logLine("2024.01.01 01:00:01", "new"))
+
+	select {
+	case rec := <-records:
+		if rec.Message != "new" {
+			t.Fatalf("rec.Message = %q, want %q", rec.Message, "new")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("notify backend did not pick up the rotated file")
+	}
+}
@@ -0,0 +1,89 @@
+package vrclog
+
+import (
+	"sync"
+	"time"
+)
+
+// FollowStatus is a snapshot of a FollowSession's progress.
+type FollowStatus struct {
+	// Path and SourceID identify the file Follow is reading. They are
+	// empty until Follow has opened a file.
+	Path     string
+	SourceID SourceID
+
+	// Offset and Line are the committed position: the cursor after the
+	// last record Follow yielded from Path.
+	Offset int64
+	Line   uint64
+
+	// Size is the size of Path when Follow last looked at it.
+	Size int64
+
+	// Pending is the length of an unterminated final fragment at Offset
+	// that Follow is holding back until its newline is written.
+	Pending int64
+
+	// LastPoll is when Follow last checked Path for growth. It is zero
+	// until the first check after the initial read.
+	LastPoll time.Time
+
+	// Rotations counts the newer files Follow has switched to.
+	Rotations int
+}
+
+// Lag returns the number of bytes of Path that Follow has seen but not
+// yet yielded, including a pending fragment.
+func (s FollowStatus) Lag() int64 {
+	if s.Size > s.Offset {
+		return s.Size - s.Offset
+	}
+	return 0
+}
+
+// FollowSession is a Follow whose progress can be inspected while it
+// runs.
+type FollowSession struct {
+	cfg FollowConfig
+
+	mu     sync.Mutex
+	status FollowStatus
+}
+
+// NewFollowSession returns a session that follows cfg. Nothing is read
+// until Records is ranged over.
+func NewFollowSession(cfg FollowConfig) *FollowSession {
+	return &FollowSession{cfg: cfg}
+}
+
+// statusYield wraps yield so that every yielded record advances the
+// committed position.
+func (s *FollowSession) statusYield(yield func(Record, error) bool) func(Record, error) bool {
+	return func(rec Record, err error) bool {
+		if err == nil {
+			s.update(func(st *FollowStatus) {
+				c := rec.Cursor()
+				st.Path = rec.Path
+				st.SourceID = rec.SourceID
+				st.Offset = c.Offset
+				st.Line = c.Line
+				st.Pending = 0
+			})
+		}
+		return yield(rec, err)
+	}
+}
+
+// Status returns the current progress. It is safe to call from any
+// goroutine, including while Records is being ranged over.
+func (s *FollowSession) Status() FollowStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+func (s *FollowSession) update(f func(*FollowStatus)) {
+	s.mu.Lock()
+	f(&s.status)
+	s.mu.Unlock()
+}
@@ -0,0 +1,100 @@
+package vrclog
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// waitStatus polls s.Status until cond holds or the deadline passes.
+func waitStatus(t *testing.T, s *FollowSession, cond func(FollowStatus) bool) FollowStatus {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		st := s.Status()
+		if cond(st) {
+			return st
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("status never reached the expected state: %+v", st)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestFollowSession_Status(t *testing.T) {
+	dir := t.TempDir()
+	line1 := logLine("2024.01.01 00:00:00", "first")
+	line2 := logLine("2024.01.01 00:00:01", "second")
+	fragment := "2024.01.01 00:00:02 Log        -  par"
+	path := writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt", line1+line2+fragment)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := NewFollowSession(FollowConfig{Directory: dir, PollInterval: testPollInterval})
+	if st := s.Status(); st.Path != "" {
+		t.Fatalf("status before start = %+v, want zero", st)
+	}
+
+	records := make(chan Record, 16)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for rec, err := range s.Records(ctx) {
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			records <- rec
+		}
+	}()
+
+	var last Record
+	for i := 0; i < 2; i++ {
+		select {
+		case last = <-records:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for records")
+		}
+	}
+
+	st := waitStatus(t, s, func(st FollowStatus) bool {
+		return !st.LastPoll.IsZero() && st.Pending == int64(len(fragment))
+	})
+	if st.Path != path || st.SourceID != last.SourceID {
+		t.Errorf("source = %s %s, want %s %s", st.Path, st.SourceID, path, last.SourceID)
+	}
+	if st.Offset != last.NextOffset || st.Line != 3 {
+		t.Errorf("position = %d/%d, want %d/3", st.Offset, st.Line, last.NextOffset)
+	}
+	if want := int64(len(line1 + line2 + fragment)); st.Size != want {
+		t.Errorf("Size = %d, want %d", st.Size, want)
+	}
+	if st.Lag() != int64(len(fragment)) {
+		t.Errorf("Lag = %d, want %d", st.Lag(), len(fragment))
+	}
+	if st.Rotations != 0 {
+		t.Errorf("Rotations = %d, want 0", st.Rotations)
+	}
+
+	newPath := writeLogFile(t, dir, "output_log_2024-01-01_01-00-00.txt", logLine("2024.01.01 01:00:00", "next"))
+	st = waitStatus(t, s, func(st FollowStatus) bool {
+		return st.Path == newPath && st.Pending == 0 && st.Line == 2
+	})
+	if st.Rotations != 1 {
+		t.Errorf("Rotations = %d, want 1", st.Rotations)
+	}
+
+	cancel()
+	<-done
+}
+
+func TestFollowStatus_Lag(t *testing.T) {
+	if lag := (FollowStatus{Offset: 10, Size: 4}).Lag(); lag != 0 {
+		t.Errorf("Lag = %d, want 0 when size is behind offset", lag)
+	}
+	if lag := (FollowStatus{Offset: 10, Size: 25}).Lag(); lag != 15 {
+		t.Errorf("Lag = %d, want 15", lag)
+	}
+}
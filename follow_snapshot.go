@@ -0,0 +1,142 @@
+package vrclog
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/vrclog/vrclog-go/internal/logfile"
+)
+
+// SnapshotMode selects what Follow does with the bytes a
+// FollowConfig.Snapshot already covers.
+type SnapshotMode uint8
+
+const (
+	// SnapshotSkip starts right after the covered bytes of the newest
+	// file the snapshot covers: at the first record LogSnapshot.Contains
+	// reports false for. Covered bytes are only scanned for newlines, so
+	// Line stays exact, and are never decoded. If the snapshot covers
+	// none of the current log files, Follow starts at the start of the
+	// oldest one.
+	SnapshotSkip SnapshotMode = iota
+
+	// SnapshotReplay starts at the start of the newest file the snapshot
+	// covers and yields its covered records too, with Record.Historical
+	// set.
+	SnapshotReplay
+)
+
+// startWithSnapshot starts following from the newest log file the
+// snapshot covers, or from the oldest log file if it covers none. Any
+// newer files are then read in order by the usual rotation handling.
+func (fs *followState) startWithSnapshot(ctx context.Context, snap LogSnapshot, mode SnapshotMode, yield func(Record, error) bool) {
+	files, ok := fs.waitForLogFiles(ctx, yield)
+	if !ok {
+		return
+	}
+
+	var cursor *Cursor
+	for i := len(files) - 1; i >= 0; i-- {
+		srcIDStr, err := fs.root.SourceID(files[i].Path)
+		if err != nil {
+			yield(Record{}, fmt.Errorf("source ID for %s: %w", files[i].Path, err))
+			return
+		}
+		covered, ok := snap.files[SourceID(srcIDStr)]
+		if !ok {
+			continue
+		}
+		cursor = &Cursor{SourceID: SourceID(srcIDStr), Path: files[i].Path, Line: 1}
+		if mode == SnapshotSkip {
+			cursor.Offset, cursor.Line, err = snapshotBoundary(fs.root, files[i].Path, covered.Size)
+			if err != nil {
+				yield(Record{}, err)
+				return
+			}
+		}
+		break
+	}
+
+	if cursor == nil {
+		srcIDStr, err := fs.root.SourceID(files[0].Path)
+		if err != nil {
+			yield(Record{}, fmt.Errorf("source ID for %s: %w", files[0].Path, err))
+			return
+		}
+		cursor = &Cursor{SourceID: SourceID(srcIDStr), Path: files[0].Path, Line: 1}
+	}
+
+	fs.startWithCursor(ctx, cursor, yield)
+}
+
+// snapshotBoundary returns the start offset and line number of the line
+// containing byte size-1 of path, or of the line starting at size if
+// byte size-1 is a newline. That is the first record whose bytes did not
+// all exist when size was captured.
+func snapshotBoundary(root logfile.Root, path string, size int64) (offset int64, line uint64, err error) {
+	f, info, err := root.OpenRegular(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	if info.Size() < size {
+		return 0, 0, fmt.Errorf("%w: file size %d < snapshot size %d", ErrSourceTruncated, info.Size(), size)
+	}
+
+	buf := make([]byte, lineReaderBufSize)
+	n, err := f.ReadAt(buf[:2], 0)
+	if err != nil && err != io.EOF {
+		return 0, 0, err
+	}
+	if enc, _, _ := detectEncoding(buf[:n]); enc != EncodingUTF8 {
+		return frameBoundary(f, size)
+	}
+
+	line = 1
+	for off := int64(0); off < size; {
+		n := min(int64(len(buf)), size-off)
+		read, err := f.ReadAt(buf[:n], off)
+		if int64(read) < n {
+			if err == nil || err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, 0, err
+		}
+		chunk := buf[:read]
+		if i := bytes.LastIndexByte(chunk, '\n'); i >= 0 {
+			offset = off + int64(i) + 1
+		}
+		line += uint64(bytes.Count(chunk, []byte{'\n'}))
+		off += int64(read)
+	}
+	return offset, line, nil
+}
+
+// frameBoundary is snapshotBoundary for a file whose newlines cannot be
+// found by scanning for '\n' bytes: it frames the lines with a
+// lineReader instead.
+func frameBoundary(r io.ReaderAt, size int64) (offset int64, line uint64, err error) {
+	lr := newLineReader(io.NewSectionReader(r, 0, size), 0, 1, recordOptions{})
+	for {
+		_, _, _, _, _, terminated, _, err := lr.next()
+		if err == io.EOF || (err == nil && !terminated) {
+			return lr.offset, lr.line, nil
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+}
+
+// historicalYield marks the records snap covers as historical.
+func historicalYield(snap LogSnapshot, yield func(Record, error) bool) func(Record, error) bool {
+	return func(rec Record, err error) bool {
+		if err == nil {
+			rec.Historical = snap.Contains(rec)
+		}
+		return yield(rec, err)
+	}
+}
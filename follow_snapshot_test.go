@@ -0,0 +1,133 @@
+package vrclog
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFollow_SnapshotSkip(t *testing.T) {
+	dir := t.TempDir()
+	line1 := logLine("2024.01.01 00:00:01", "old one")
+	line2 := logLine("2024.01.01 00:00:02", "old two")
+	partial := "2024.01.01 00:00:03 Log        -  spl"
+	path := writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt", line1+line2+partial)
+
+	snap, err := CaptureLogSnapshot(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendToFile(t, path, "it\n"+logLine("2024.01.01 00:00:04", "new"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	records, errs := collectRecords(t, ctx, FollowConfig{Directory: dir, PollInterval: testPollInterval, Snapshot: &snap}, 2)
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	// The line that was incomplete at capture time is not covered.
+	if records[0].Message != "split" || records[0].Offset != int64(len(line1+line2)) || records[0].Line != 3 {
+		t.Errorf("first record = %q at %d line %d, want \"split\" at %d line 3",
+			records[0].Message, records[0].Offset, records[0].Line, len(line1+line2))
+	}
+	if records[1].Message != "new" || records[1].Line != 4 {
+		t.Errorf("second record = %q line %d, want \"new\" line 4", records[1].Message, records[1].Line)
+	}
+	for _, rec := range records {
+		if rec.Historical || snap.Contains(rec) {
+			t.Errorf("record %q should be live", rec.Message)
+		}
+	}
+}
+
+func TestFollow_SnapshotReplay(t *testing.T) {
+	dir := t.TempDir()
+	path := writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt",
+		logLine("2024.01.01 00:00:01", "old one")+logLine("2024.01.01 00:00:02", "old two"))
+
+	snap, err := CaptureLogSnapshot(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendToFile(t, path, logLine("2024.01.01 00:00:03", "new"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	records, errs := collectRecords(t, ctx, FollowConfig{
+		Directory:    dir,
+		PollInterval: testPollInterval,
+		Snapshot:     &snap,
+		SnapshotMode: SnapshotReplay,
+	}, 3)
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want 3", len(records))
+	}
+	for i, want := range []bool{true, true, false} {
+		if records[i].Historical != want {
+			t.Errorf("record %d (%q) Historical = %v, want %v", i, records[i].Message, records[i].Historical, want)
+		}
+	}
+}
+
+func TestFollow_SnapshotContinuesIntoNewerFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt", logLine("2024.01.01 00:00:01", "covered"))
+
+	snap, err := CaptureLogSnapshot(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeLogFile(t, dir, "output_log_2024-01-01_01-00-00.txt", logLine("2024.01.01 01:00:00", "after capture"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	records, errs := collectRecords(t, ctx, FollowConfig{Directory: dir, PollInterval: testPollInterval, Snapshot: &snap}, 1)
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if len(records) != 1 || records[0].Message != "after capture" {
+		t.Fatalf("records = %+v, want only the record written after capture", records)
+	}
+}
+
+func TestFollow_EmptySnapshotStartsAtOldestFile(t *testing.T) {
+	dir := t.TempDir()
+	snap, err := CaptureLogSnapshot(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt", logLine("2024.01.01 00:00:01", "first file"))
+	writeLogFile(t, dir, "output_log_2024-01-01_01-00-00.txt", logLine("2024.01.01 01:00:00", "second file"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	records, errs := collectRecords(t, ctx, FollowConfig{Directory: dir, PollInterval: testPollInterval, Snapshot: &snap}, 2)
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if len(records) != 2 || records[0].Message != "first file" || records[1].Message != "second file" {
+		t.Fatalf("records = %+v, want both files in order", records)
+	}
+}
+
+func TestFollow_SnapshotAndCursorExclusive(t *testing.T) {
+	snap := LogSnapshot{}
+	_, errs := collectRecords(t, context.Background(), FollowConfig{
+		Directory: t.TempDir(),
+		Cursor:    &Cursor{},
+		Snapshot:  &snap,
+	}, 1)
+	if len(errs) != 1 {
+		t.Fatalf("got %v, want one validation error", errs)
+	}
+}
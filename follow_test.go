@@ -4,12 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/vrclog/vrclog-go/internal/logfile"
@@ -866,7 +869,7 @@ func TestFollow_OversizedIncompleteLineNotEmitted(t *testing.T) {
 	}()
 
 	header := "2024.01.01 00:00:01 Log        -  "
-	bigMsg := strings.Repeat("X", maxLineSize+100)
+	bigMsg := strings.Repeat("X", DefaultMaxLineSize+100)
 	// Write the oversized content WITHOUT a trailing newline first.
 	appendToFile(t, path, header+bigMsg)
 	time.Sleep(5 * testPollInterval)
@@ -947,6 +950,125 @@ func TestFollow_TruncationReturnsErrSourceTruncated(t *testing.T) {
 	}
 }
 
+func TestFollow_TruncationRewind(t *testing.T) {
+	dir := t.TempDir()
+	line1 := logLine("2024.01.01 00:00:01", "line one")
+	line2 := logLine("2024.01.01 00:00:02", "line two")
+	path := writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt", line1+line2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	replacement := logLine("2024.01.01 00:00:03", "new")
+	var notes []SourceNotification
+	var records []Record
+	for rec, err := range Follow(ctx, FollowConfig{
+		Directory:    dir,
+		PollInterval: testPollInterval,
+		Truncation:   TruncationRewind,
+		OnSource:     func(n SourceNotification) { notes = append(notes, n) },
+	}) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		records = append(records, rec)
+		if len(records) == 2 {
+			if err := os.WriteFile(path, []byte(replacement), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if len(records) == 3 {
+			break
+		}
+	}
+
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want 3", len(records))
+	}
+	if records[2].Message != "new" || records[2].Offset != 0 || records[2].Line != 1 {
+		t.Errorf("re-read record = %+v, want \"new\" at offset 0 line 1", records[2])
+	}
+
+	var trunc *SourceNotification
+	for i := range notes {
+		if notes[i].Kind == SourceTruncated {
+			trunc = &notes[i]
+		}
+	}
+	if trunc == nil {
+		t.Fatalf("no source_truncated notification in %+v", notes)
+	}
+	committed := int64(len(line1 + line2))
+	if trunc.Offset != committed || trunc.Line != 3 {
+		t.Errorf("truncated at %d/%d, want %d/3", trunc.Offset, trunc.Line, committed)
+	}
+	// Everything the smaller replacement holds was at offsets already
+	// yielded, so all of it is reread.
+	want := TruncationInfo{
+		Size:   int64(len(replacement)),
+		Reread: ByteRange{Start: 0, End: min(int64(len(replacement)), committed)},
+	}
+	if *trunc.Truncation != want {
+		t.Errorf("truncation = %+v, want %+v", *trunc.Truncation, want)
+	}
+}
+
+func TestFollow_TruncationSkipToNext(t *testing.T) {
+	dir := t.TempDir()
+	content := logLine("2024.01.01 00:00:01", "line one") +
+		logLine("2024.01.01 00:00:02", "line two")
+	path := writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt", content)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var kinds []SourceNotificationKind
+	var records []Record
+	truncated := false
+	for rec, err := range Follow(ctx, FollowConfig{
+		Directory:    dir,
+		PollInterval: testPollInterval,
+		Truncation:   TruncationSkipToNext,
+		OnSource: func(n SourceNotification) {
+			kinds = append(kinds, n.Kind)
+			if n.Kind == SourceTruncated && !truncated {
+				truncated = true
+				// Anything written to the abandoned file is ignored.
+				appendToFile(t, path, logLine("2024.01.01 00:00:03", "ignored"))
+				writeLogFile(t, dir, "output_log_2024-01-01_01-00-00.txt", logLine("2024.01.01 01:00:00", "next file"))
+			}
+		},
+	}) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		records = append(records, rec)
+		if len(records) == 2 {
+			if err := os.Truncate(path, 5); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if len(records) == 3 {
+			break
+		}
+	}
+
+	if len(records) != 3 || records[2].Message != "next file" {
+		t.Fatalf("records = %+v, want the next file's record third", records)
+	}
+	want := []SourceNotificationKind{SourceOpened, SourceTruncated, SourceRotationDetected, SourceOpened}
+	if !slices.Equal(kinds, want) {
+		t.Errorf("notifications = %v, want %v", kinds, want)
+	}
+}
+
+func TestFollow_UnknownTruncationPolicy(t *testing.T) {
+	_, errs := collectRecords(t, context.Background(), FollowConfig{Directory: t.TempDir(), Truncation: 99}, 1)
+	if len(errs) != 1 {
+		t.Fatalf("got %v, want one validation error", errs)
+	}
+}
+
 func TestFollow_StatErrorPropagated(t *testing.T) {
 	dir := t.TempDir()
 	path := writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt",
@@ -1205,3 +1327,46 @@ func TestFollow_RotationWithUnterminatedDoesNotStarve(t *testing.T) {
 		t.Errorf("records[1].Message = %q, want %q", records[1].Message, "new file line")
 	}
 }
+
+func TestFollow_FS(t *testing.T) {
+	dir := t.TempDir()
+	path := writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt", logLine("2024.01.01 00:00:01", "first"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cfg := FollowConfig{FS: os.DirFS(dir), PollInterval: testPollInterval, Backend: FollowBackendNotify}
+	var records []Record
+	for rec, err := range Follow(ctx, cfg) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		records = append(records, rec)
+		if len(records) == 1 {
+			appendToFile(t, path, logLine("2024.01.01 00:00:02", "second"))
+			continue
+		}
+		break
+	}
+	if len(records) != 2 || records[1].Message != "second" {
+		t.Fatalf("records = %+v", records)
+	}
+	if records[0].Path != "output_log_2024-01-01_00-00-00.txt" {
+		t.Errorf("Path = %q, want the FS name", records[0].Path)
+	}
+
+	cursor := records[0].Cursor()
+	cfg.Cursor = &cursor
+	resumed, errs := collectRecords(t, ctx, cfg, 1)
+	if len(errs) != 0 || len(resumed) != 1 || resumed[0].ID != records[1].ID {
+		t.Fatalf("resumed = %+v, errs = %v", resumed, errs)
+	}
+}
+
+func TestFollow_FSWithoutStat(t *testing.T) {
+	fsys := struct{ fs.FS }{fstest.MapFS{}}
+	_, errs := collectRecords(t, context.Background(), FollowConfig{FS: fsys}, 1)
+	if len(errs) != 1 {
+		t.Fatalf("got %v, want one error for an FS without fs.StatFS", errs)
+	}
+}
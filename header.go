@@ -1,6 +1,7 @@
 package vrclog
 
 import (
+	"fmt"
 	"strings"
 	"time"
 )
@@ -9,6 +10,163 @@ const headerTimestampLen = 19
 const headerTimestampLayout = "2006.01.02 15:04:05"
 const headerSeparator = " -  "
 
+// wallClockLayout formats the fields of a wall time for comparison.
+const wallClockLayout = "2006-01-02 15:04:05.999999999"
+
+// Header is the decoded header of a log line.
+type Header struct {
+	Time    time.Time
+	Level   Level
+	Message string
+
+	// Issue is a RecordIssueAmbiguousTime or RecordIssueNonexistentTime
+	// issue for a wall time around a DST transition, or nil.
+	Issue *RecordIssue
+}
+
+// HeaderDecoder splits a log line into its header and message. The read
+// and follow configs take one for logs whose headers differ from
+// VRChat's release builds; nil means DefaultHeaderDecoder.
+type HeaderDecoder interface {
+	// DecodeHeader decodes line, the text of one physical line, reading
+	// wall times in loc, which is never nil. ok is false for a line
+	// without a header, such as the continuation of a stack trace; the
+	// record then gets LevelUnknown, a zero Time and the whole line as
+	// Message.
+	DecodeHeader(line string, loc *time.Location) (h Header, ok bool)
+}
+
+// The built-in header decoders.
+var (
+	// DefaultHeaderDecoder decodes the headers of VRChat release builds:
+	// "2006.01.02 15:04:05 Log        -  message".
+	DefaultHeaderDecoder HeaderDecoder = vrchatHeaderDecoder{}
+
+	// MillisecondHeaderDecoder decodes VRChat headers whose time has a
+	// fractional second: "2006.01.02 15:04:05.123 Log        -  message".
+	// Lines without the fraction decode too.
+	MillisecondHeaderDecoder HeaderDecoder = LayoutHeaderDecoder{Layout: headerTimestampLayout, Separator: "-"}
+
+	// BetaHeaderDecoder decodes the header variants of VRChat beta
+	// builds: dates separated by '.' or '-', an optional fractional
+	// second, any padding around the '-' after the level, and level
+	// words in any case.
+	BetaHeaderDecoder HeaderDecoder = headerDecoders{
+		LayoutHeaderDecoder{Layout: headerTimestampLayout, Separator: "-"},
+		LayoutHeaderDecoder{Layout: "2006-01-02 15:04:05", Separator: "-"},
+	}
+
+	// UnityEditorHeaderDecoder decodes Unity editor logs written with the
+	// -timestamps option, as from test runs of a world:
+	// "2006-01-02T15:04:05.123Z|0x1a2b|message". Their times are UTC and
+	// their lines carry no level, so records get LevelUnknown.
+	UnityEditorHeaderDecoder HeaderDecoder = unityHeaderDecoder{}
+)
+
+// LayoutHeaderDecoder decodes headers of the form
+// "<time> <level> <Separator> <message>".
+type LayoutHeaderDecoder struct {
+	// Layout is the time.Parse layout of the time starting the line,
+	// which spans as many space-separated fields as Layout. A fractional
+	// second after the seconds is accepted even if Layout has none.
+	Layout string
+
+	// Separator ends the level column. Spaces around it are dropped.
+	// Empty means the line has no level column: the message follows the
+	// time.
+	Separator string
+
+	// Levels maps level words, compared case-insensitively, to levels.
+	// Nil means VRChat's words. Other words decode as LevelUnknown.
+	Levels map[string]Level
+}
+
+// DecodeHeader implements HeaderDecoder.
+func (d LayoutHeaderDecoder) DecodeHeader(line string, loc *time.Location) (Header, bool) {
+	end := -1
+	for range strings.Count(d.Layout, " ") + 1 {
+		i := strings.IndexByte(line[end+1:], ' ')
+		if i < 0 {
+			return Header{}, false
+		}
+		end += i + 1
+	}
+	t, issue, err := parseWallClock(d.Layout, line[:end], loc)
+	if err != nil {
+		return Header{}, false
+	}
+	rest := line[end+1:]
+	if d.Separator == "" {
+		return Header{Time: t, Level: LevelUnknown, Message: rest, Issue: issue}, true
+	}
+
+	i := strings.Index(rest, d.Separator)
+	if i < 0 {
+		return Header{}, false
+	}
+	levels := d.Levels
+	if levels == nil {
+		levels = levelMap
+	}
+	return Header{
+		Time:    t,
+		Level:   lookupLevel(levels, strings.TrimSpace(rest[:i])),
+		Message: strings.TrimLeft(rest[i+len(d.Separator):], " "),
+		Issue:   issue,
+	}, true
+}
+
+// lookupLevel returns the level of word in levels, ignoring case.
+func lookupLevel(levels map[string]Level, word string) Level {
+	if lvl, ok := levels[word]; ok {
+		return lvl
+	}
+	for w, lvl := range levels {
+		if strings.EqualFold(w, word) {
+			return lvl
+		}
+	}
+	return LevelUnknown
+}
+
+// headerDecoders decodes a line with the first of its decoders that
+// accepts it.
+type headerDecoders []HeaderDecoder
+
+func (ds headerDecoders) DecodeHeader(line string, loc *time.Location) (Header, bool) {
+	for _, d := range ds {
+		if h, ok := d.DecodeHeader(line, loc); ok {
+			return h, true
+		}
+	}
+	return Header{}, false
+}
+
+type vrchatHeaderDecoder struct{}
+
+func (vrchatHeaderDecoder) DecodeHeader(line string, loc *time.Location) (Header, bool) {
+	t, level, message, issue, ok := decodeHeader(line, loc)
+	return Header{Time: t, Level: level, Message: message, Issue: issue}, ok
+}
+
+type unityHeaderDecoder struct{}
+
+func (unityHeaderDecoder) DecodeHeader(line string, _ *time.Location) (Header, bool) {
+	stamp, rest, ok := strings.Cut(line, "|")
+	if !ok {
+		return Header{}, false
+	}
+	thread, message, ok := strings.Cut(rest, "|")
+	if !ok || !strings.HasPrefix(thread, "0x") {
+		return Header{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, stamp)
+	if err != nil {
+		return Header{}, false
+	}
+	return Header{Time: t, Level: LevelUnknown, Message: message}, true
+}
+
 var levelMap = map[string]Level{
 	"Log":       LevelLog,
 	"Warning":   LevelWarning,
@@ -17,23 +175,27 @@ var levelMap = map[string]Level{
 	"Exception": LevelException,
 }
 
-func decodeHeader(raw string, loc *time.Location) (t time.Time, level Level, message string, ok bool) {
+// decodeHeader splits a VRChat header line into its timestamp, level
+// and message, interpreting the timestamp as wall-clock time in loc
+// (time.Local if nil). timeIssue is non-nil when that wall time falls in
+// a DST gap or overlap in loc; see resolveWallClock.
+func decodeHeader(raw string, loc *time.Location) (t time.Time, level Level, message string, timeIssue *RecordIssue, ok bool) {
 	if loc == nil {
 		loc = time.Local
 	}
 
 	if len(raw) < headerTimestampLen {
-		return time.Time{}, LevelUnknown, "", false
+		return time.Time{}, LevelUnknown, "", nil, false
 	}
 
-	t, err := time.ParseInLocation(headerTimestampLayout, raw[:headerTimestampLen], loc)
+	t, timeIssue, err := parseWallClock(headerTimestampLayout, raw[:headerTimestampLen], loc)
 	if err != nil {
-		return time.Time{}, LevelUnknown, "", false
+		return time.Time{}, LevelUnknown, "", nil, false
 	}
 
 	sepIdx := strings.Index(raw[headerTimestampLen:], headerSeparator)
 	if sepIdx < 0 {
-		return time.Time{}, LevelUnknown, "", false
+		return time.Time{}, LevelUnknown, "", nil, false
 	}
 	sepIdx += headerTimestampLen
 
@@ -46,5 +208,59 @@ func decodeHeader(raw string, loc *time.Location) (t time.Time, level Level, mes
 
 	message = raw[sepIdx+len(headerSeparator):]
 
-	return t, lvl, message, true
+	return t, lvl, message, timeIssue, true
+}
+
+// parseWallClock parses text, a wall time in layout, in loc and resolves
+// it with resolveWallClock.
+func parseWallClock(layout, text string, loc *time.Location) (time.Time, *RecordIssue, error) {
+	t, err := time.ParseInLocation(layout, text, loc)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	wall, err := time.Parse(layout, text)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	t, issue := resolveWallClock(t, wall.Format(wallClockLayout), text)
+	return t, issue, nil
+}
+
+// resolveWallClock checks that the wall time text, already parsed into
+// t, names exactly one instant in t's location. wall is text's fields in
+// wallClockLayout. A wall time skipped by a
+// DST gap is returned as time.ParseInLocation normalized it with a
+// RecordIssueNonexistentTime issue. A wall time repeated by a DST
+// overlap is resolved to the earlier of its two instants with a
+// RecordIssueAmbiguousTime issue.
+func resolveWallClock(t time.Time, wall, text string) (time.Time, *RecordIssue) {
+	if t.Format(wallClockLayout) != wall {
+		return t, &RecordIssue{
+			Code:    RecordIssueNonexistentTime,
+			Message: fmt.Sprintf("local time %s does not exist in %s", text, t.Location()),
+		}
+	}
+
+	// Offsets change at most once around any instant in practice, so
+	// probing half a day either side finds the other offset of an
+	// overlap if there is one.
+	_, offset := t.Zone()
+	for _, probe := range []time.Time{t.Add(-12 * time.Hour), t.Add(12 * time.Hour)} {
+		_, other := probe.Zone()
+		if other == offset {
+			continue
+		}
+		alt := t.Add(time.Duration(offset-other) * time.Second)
+		if _, altOffset := alt.Zone(); altOffset != other || alt.Format(wallClockLayout) != wall {
+			continue
+		}
+		if alt.Before(t) {
+			t = alt
+		}
+		return t, &RecordIssue{
+			Code:    RecordIssueAmbiguousTime,
+			Message: fmt.Sprintf("local time %s occurs twice in %s; using the earlier instant", text, t.Location()),
+		}
+	}
+	return t, nil
 }
@@ -9,7 +9,7 @@ var testLoc = time.FixedZone("TEST", 0)
 
 func TestDecodeHeader_Log(t *testing.T) {
 	raw := "2026.08.18 12:00:00 Log        -  [Behaviour] Entering Room: Test World"
-	ts, level, message, ok := decodeHeader(raw, testLoc)
+	ts, level, message, _, ok := decodeHeader(raw, testLoc)
 	if !ok {
 		t.Fatal("expected ok=true")
 	}
@@ -27,7 +27,7 @@ func TestDecodeHeader_Log(t *testing.T) {
 
 func TestDecodeHeader_Warning(t *testing.T) {
 	raw := "2026.08.18 12:00:00 Warning    -  something went wrong"
-	_, level, message, ok := decodeHeader(raw, testLoc)
+	_, level, message, _, ok := decodeHeader(raw, testLoc)
 	if !ok {
 		t.Fatal("expected ok=true")
 	}
@@ -41,7 +41,7 @@ func TestDecodeHeader_Warning(t *testing.T) {
 
 func TestDecodeHeader_Error(t *testing.T) {
 	raw := "2026.08.18 12:00:00 Error      -  fatal error occurred"
-	_, level, _, ok := decodeHeader(raw, testLoc)
+	_, level, _, _, ok := decodeHeader(raw, testLoc)
 	if !ok {
 		t.Fatal("expected ok=true")
 	}
@@ -52,7 +52,7 @@ func TestDecodeHeader_Error(t *testing.T) {
 
 func TestDecodeHeader_Debug(t *testing.T) {
 	raw := "2026.08.18 12:00:00 Debug      -  debug info"
-	_, level, _, ok := decodeHeader(raw, testLoc)
+	_, level, _, _, ok := decodeHeader(raw, testLoc)
 	if !ok {
 		t.Fatal("expected ok=true")
 	}
@@ -63,7 +63,7 @@ func TestDecodeHeader_Debug(t *testing.T) {
 
 func TestDecodeHeader_Exception(t *testing.T) {
 	raw := "2026.08.18 12:00:00 Exception  -  stack trace here"
-	_, level, _, ok := decodeHeader(raw, testLoc)
+	_, level, _, _, ok := decodeHeader(raw, testLoc)
 	if !ok {
 		t.Fatal("expected ok=true")
 	}
@@ -74,7 +74,7 @@ func TestDecodeHeader_Exception(t *testing.T) {
 
 func TestDecodeHeader_UnrecognizedLevel(t *testing.T) {
 	raw := "2026.08.18 12:00:00 Trace      -  some trace"
-	_, level, message, ok := decodeHeader(raw, testLoc)
+	_, level, message, _, ok := decodeHeader(raw, testLoc)
 	if !ok {
 		t.Fatal("expected ok=true for unrecognized level")
 	}
@@ -88,7 +88,7 @@ func TestDecodeHeader_UnrecognizedLevel(t *testing.T) {
 
 func TestDecodeHeader_InvalidTimestamp(t *testing.T) {
 	raw := "not-a-timestamp Log        -  message"
-	_, _, _, ok := decodeHeader(raw, testLoc)
+	_, _, _, _, ok := decodeHeader(raw, testLoc)
 	if ok {
 		t.Error("expected ok=false for invalid timestamp")
 	}
@@ -96,7 +96,7 @@ func TestDecodeHeader_InvalidTimestamp(t *testing.T) {
 
 func TestDecodeHeader_TooShort(t *testing.T) {
 	raw := "short"
-	_, _, _, ok := decodeHeader(raw, testLoc)
+	_, _, _, _, ok := decodeHeader(raw, testLoc)
 	if ok {
 		t.Error("expected ok=false for too-short input")
 	}
@@ -104,7 +104,7 @@ func TestDecodeHeader_TooShort(t *testing.T) {
 
 func TestDecodeHeader_NoSeparator(t *testing.T) {
 	raw := "2026.08.18 12:00:00 Log no separator here"
-	_, _, _, ok := decodeHeader(raw, testLoc)
+	_, _, _, _, ok := decodeHeader(raw, testLoc)
 	if ok {
 		t.Error("expected ok=false when separator is missing")
 	}
@@ -112,7 +112,7 @@ func TestDecodeHeader_NoSeparator(t *testing.T) {
 
 func TestDecodeHeader_MessageExtraction(t *testing.T) {
 	raw := "2026.08.18 12:00:00 Log        -  [Video Playback] Attempting to resolve URL 'https://example.com/video.mp4'"
-	_, _, message, ok := decodeHeader(raw, testLoc)
+	_, _, message, _, ok := decodeHeader(raw, testLoc)
 	if !ok {
 		t.Fatal("expected ok=true")
 	}
@@ -124,7 +124,7 @@ func TestDecodeHeader_MessageExtraction(t *testing.T) {
 
 func TestDecodeHeader_UnicodeMessage(t *testing.T) {
 	raw := "2026.08.18 12:00:00 Log        -  [Behaviour] OnPlayerJoined テストユーザー"
-	_, _, message, ok := decodeHeader(raw, testLoc)
+	_, _, message, _, ok := decodeHeader(raw, testLoc)
 	if !ok {
 		t.Fatal("expected ok=true")
 	}
@@ -136,7 +136,7 @@ func TestDecodeHeader_UnicodeMessage(t *testing.T) {
 
 func TestDecodeHeader_EmptyMessage(t *testing.T) {
 	raw := "2026.08.18 12:00:00 Log        -  "
-	_, level, message, ok := decodeHeader(raw, testLoc)
+	_, level, message, _, ok := decodeHeader(raw, testLoc)
 	if !ok {
 		t.Fatal("expected ok=true")
 	}
@@ -150,7 +150,7 @@ func TestDecodeHeader_EmptyMessage(t *testing.T) {
 
 func TestDecodeHeader_NilLocation(t *testing.T) {
 	raw := "2026.08.18 12:00:00 Log        -  msg"
-	_, level, _, ok := decodeHeader(raw, nil)
+	_, level, _, _, ok := decodeHeader(raw, nil)
 	if !ok {
 		t.Fatal("expected ok=true with nil location")
 	}
@@ -158,3 +158,164 @@ func TestDecodeHeader_NilLocation(t *testing.T) {
 		t.Errorf("level = %q, want %q", level, LevelLog)
 	}
 }
+
+func loadTestLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+	return loc
+}
+
+func TestDecodeHeader_Location(t *testing.T) {
+	tokyo := loadTestLocation(t, "Asia/Tokyo")
+	raw := "2026.08.18 12:00:00 Log        -  msg"
+	ts, _, _, issue, ok := decodeHeader(raw, tokyo)
+	if !ok {
+		t.Fatal("expected ok=true")
+	}
+	if issue != nil {
+		t.Errorf("unexpected issue %+v", issue)
+	}
+	want := time.Date(2026, 8, 18, 3, 0, 0, 0, time.UTC)
+	if !ts.Equal(want) {
+		t.Errorf("time = %v, want %v", ts.UTC(), want)
+	}
+}
+
+func TestDecodeHeader_AmbiguousTime(t *testing.T) {
+	ny := loadTestLocation(t, "America/New_York")
+	// 01:30 occurs twice on 2026-11-01: first at EDT, then at EST.
+	raw := "2026.11.01 01:30:00 Log        -  msg"
+	ts, _, _, issue, ok := decodeHeader(raw, ny)
+	if !ok {
+		t.Fatal("expected ok=true")
+	}
+	if issue == nil || issue.Code != RecordIssueAmbiguousTime {
+		t.Fatalf("issue = %+v, want %s", issue, RecordIssueAmbiguousTime)
+	}
+	want := time.Date(2026, 11, 1, 5, 30, 0, 0, time.UTC)
+	if !ts.Equal(want) {
+		t.Errorf("time = %v, want earlier instant %v", ts.UTC(), want)
+	}
+}
+
+func TestDecodeHeader_NonexistentTime(t *testing.T) {
+	ny := loadTestLocation(t, "America/New_York")
+	// 02:30 is skipped on 2026-03-08 when clocks jump to 03:00.
+	raw := "2026.03.08 02:30:00 Log        -  msg"
+	_, _, _, issue, ok := decodeHeader(raw, ny)
+	if !ok {
+		t.Fatal("expected ok=true")
+	}
+	if issue == nil || issue.Code != RecordIssueNonexistentTime {
+		t.Fatalf("issue = %+v, want %s", issue, RecordIssueNonexistentTime)
+	}
+}
+
+func TestDecodeHeader_AroundTransitions(t *testing.T) {
+	ny := loadTestLocation(t, "America/New_York")
+	for _, raw := range []string{
+		"2026.03.08 01:59:59 Log        -  msg",
+		"2026.03.08 03:00:00 Log        -  msg",
+		"2026.11.01 00:59:59 Log        -  msg",
+		"2026.11.01 02:00:00 Log        -  msg",
+	} {
+		if _, _, _, issue, ok := decodeHeader(raw, ny); !ok || issue != nil {
+			t.Errorf("%q: ok=%v issue=%+v, want ok and no issue", raw, ok, issue)
+		}
+	}
+}
+
+func TestHeaderDecoders(t *testing.T) {
+	tests := []struct {
+		name    string
+		dec     HeaderDecoder
+		line    string
+		ok      bool
+		time    time.Time
+		level   Level
+		message string
+	}{
+		{"default", DefaultHeaderDecoder, "2026.08.18 12:00:00 Log        -  msg", true,
+			time.Date(2026, 8, 18, 12, 0, 0, 0, testLoc), LevelLog, "msg"},
+		{"ms", MillisecondHeaderDecoder, "2026.08.18 12:00:00.125 Warning    -  msg", true,
+			time.Date(2026, 8, 18, 12, 0, 0, 125e6, testLoc), LevelWarning, "msg"},
+		{"ms without fraction", MillisecondHeaderDecoder, "2026.08.18 12:00:00 Error      -  msg", true,
+			time.Date(2026, 8, 18, 12, 0, 0, 0, testLoc), LevelError, "msg"},
+		{"ms continuation", MillisecondHeaderDecoder, "  at Foo.Bar () [0x00000] in <file>:0", false,
+			time.Time{}, "", ""},
+		{"beta dashes", BetaHeaderDecoder, "2026-08-18 12:00:00 log - msg - more", true,
+			time.Date(2026, 8, 18, 12, 0, 0, 0, testLoc), LevelLog, "msg - more"},
+		{"beta dots", BetaHeaderDecoder, "2026.08.18 12:00:00.5 EXCEPTION   -   msg", true,
+			time.Date(2026, 8, 18, 12, 0, 0, 5e8, testLoc), LevelException, "msg"},
+		{"beta unknown level", BetaHeaderDecoder, "2026.08.18 12:00:00 Assert -  msg", true,
+			time.Date(2026, 8, 18, 12, 0, 0, 0, testLoc), LevelUnknown, "msg"},
+		{"unity", UnityEditorHeaderDecoder, "2026-08-18T12:00:00.250Z|0x1a2b|msg|with pipes", true,
+			time.Date(2026, 8, 18, 12, 0, 0, 250e6, time.UTC), LevelUnknown, "msg|with pipes"},
+		{"unity rejects vrchat", UnityEditorHeaderDecoder, "2026.08.18 12:00:00 Log        -  msg", false,
+			time.Time{}, "", ""},
+		{"layout without level", LayoutHeaderDecoder{Layout: "15:04:05 2006/01/02"}, "12:00:00 2026/08/18 msg", true,
+			time.Date(2026, 8, 18, 12, 0, 0, 0, testLoc), LevelUnknown, "msg"},
+		{"layout levels", LayoutHeaderDecoder{Layout: headerTimestampLayout, Separator: ":", Levels: map[string]Level{"W": LevelWarning}},
+			"2026.08.18 12:00:00 w: msg", true, time.Date(2026, 8, 18, 12, 0, 0, 0, testLoc), LevelWarning, "msg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, ok := tt.dec.DecodeHeader(tt.line, testLoc)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			if !h.Time.Equal(tt.time) || h.Level != tt.level || h.Message != tt.message || h.Issue != nil {
+				t.Errorf("header = %+v, want %v %s %q", h, tt.time, tt.level, tt.message)
+			}
+		})
+	}
+}
+
+func TestLayoutHeaderDecoder_AmbiguousTime(t *testing.T) {
+	ny := loadTestLocation(t, "America/New_York")
+	h, ok := MillisecondHeaderDecoder.DecodeHeader("2026.11.01 01:30:00.5 Log        -  msg", ny)
+	if !ok {
+		t.Fatal("expected ok=true")
+	}
+	if h.Issue == nil || h.Issue.Code != RecordIssueAmbiguousTime {
+		t.Fatalf("issue = %+v, want %s", h.Issue, RecordIssueAmbiguousTime)
+	}
+	want := time.Date(2026, 11, 1, 5, 30, 0, 5e8, time.UTC)
+	if !h.Time.Equal(want) {
+		t.Errorf("time = %v, want earlier instant %v", h.Time.UTC(), want)
+	}
+}
+
+func TestReadFile_HeaderDecoder(t *testing.T) {
+	dir := t.TempDir()
+	path := writeLog(t, dir,
+		"2026.08.18 12:00:00.100 Log        -  first",
+		"  continuation",
+		"2026.08.18 12:00:01.200 Warning    -  second",
+	)
+
+	records := readAll(t, ReadFileConfig{Path: path, Location: testLoc, Header: MillisecondHeaderDecoder})
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want 3", len(records))
+	}
+	if records[0].Message != "first" || !records[0].Time.Equal(time.Date(2026, 8, 18, 12, 0, 0, 1e8, testLoc)) {
+		t.Errorf("record 0 = %+v", records[0])
+	}
+	if records[1].Level != LevelUnknown || !records[1].Time.IsZero() || records[1].Message != "  continuation" {
+		t.Errorf("record 1 = %+v, want a continuation line", records[1])
+	}
+	if records[2].Level != LevelWarning {
+		t.Errorf("record 2 level = %s", records[2].Level)
+	}
+
+	// The default decoder does not know the fractional layout.
+	if records := readAll(t, ReadFileConfig{Path: path, Location: testLoc}); records[0].Level != LevelUnknown {
+		t.Errorf("default decoder level = %s, want unknown", records[0].Level)
+	}
+}
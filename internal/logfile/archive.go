@@ -0,0 +1,219 @@
+package logfile
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+var ErrArchiveEntryNotFound = errors.New("archive entry not found")
+
+var ErrAmbiguousArchiveEntry = errors.New("archive holds more than one log file")
+
+type Compression uint8
+
+const (
+	CompressionNone Compression = iota
+	CompressionGzip
+	CompressionZip
+)
+
+// CompressionOf classifies path by its file name suffix.
+func CompressionOf(path string) Compression {
+	lower := strings.ToLower(path)
+	switch {
+	case strings.HasSuffix(lower, ".gz"):
+		return CompressionGzip
+	case strings.HasSuffix(lower, ".zip"):
+		return CompressionZip
+	default:
+		return CompressionNone
+	}
+}
+
+// LogicalPath returns the path the original, uncompressed log file had:
+// the .gz suffix removed, or the zip entry's base name placed next to
+// the archive. SourceIDs of archived logs are derived from this path so
+// that an archived file yields the same RecordIDs as the original .txt
+// in the same directory. It works on host paths and fs.FS names alike.
+// Zip entries sharing a base name are therefore neither listed nor
+// opened.
+func LogicalPath(path, entry string) string {
+	switch CompressionOf(path) {
+	case CompressionGzip:
+		return path[:len(path)-len(".gz")]
+	case CompressionZip:
+		dir := path[:strings.LastIndexAny(path, pathSeparators)+1]
+		return dir + pathBase(entry)
+	default:
+		return path
+	}
+}
+
+// pathSeparators are the separators a host path or fs.FS name may use.
+const pathSeparators = string(filepath.Separator) + "/"
+
+// pathBase returns the last element of a zip entry name, which always
+// uses forward slashes regardless of the host OS.
+func pathBase(entry string) string {
+	return path.Base(strings.ReplaceAll(entry, `\`, "/"))
+}
+
+// ArchivedLog is an open stream of uncompressed log bytes.
+type ArchivedLog struct {
+	io.Reader
+	// Entry is the zip entry that was opened, or "" for gzip files.
+	Entry string
+	// Size is the uncompressed size, or -1 if the format does not
+	// record it up front (gzip).
+	Size    int64
+	closers []io.Closer
+}
+
+func (a *ArchivedLog) Close() error {
+	var first error
+	for i := len(a.closers) - 1; i >= 0; i-- {
+		if err := a.closers[i].Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// OpenArchived opens a gzip-compressed log, or one entry of a zip
+// archive. The archive itself is opened with Open; gzip files are read
+// sequentially, while zip archives need random access. For zip
+// archives an empty entry selects the only output_log entry, and it is
+// an error if there is not exactly one.
+func OpenArchived(path, entry string) (*ArchivedLog, error) {
+	return Root{}.OpenArchived(path, entry)
+}
+
+// OpenArchived is OpenArchived in r.
+func (r Root) OpenArchived(path, entry string) (*ArchivedLog, error) {
+	f, info, err := r.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch CompressionOf(path) {
+	case CompressionGzip:
+		if entry != "" {
+			f.Close()
+			return nil, fmt.Errorf("%s: gzip files have no entries", path)
+		}
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return &ArchivedLog{Reader: zr, Size: -1, closers: []io.Closer{f, zr}}, nil
+
+	case CompressionZip:
+		ra, ok := f.(io.ReaderAt)
+		if !ok {
+			f.Close()
+			return nil, fmt.Errorf("%s: %w", path, ErrNoRandomAccess)
+		}
+		zr, err := zip.NewReader(ra, info.Size())
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		zf, err := findZipEntry(zr, entry)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		rc, err := zf.Open()
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("%s: %s: %w", path, zf.Name, err)
+		}
+		return &ArchivedLog{Reader: rc, Entry: zf.Name, Size: int64(zf.UncompressedSize64), closers: []io.Closer{f, rc}}, nil
+
+	default:
+		f.Close()
+		return nil, fmt.Errorf("%s: not a recognised archive", path)
+	}
+}
+
+func findZipEntry(zr *zip.Reader, entry string) (*zip.File, error) {
+	if entry != "" {
+		for _, zf := range zr.File {
+			if zf.Name != entry {
+				continue
+			}
+			if zipBaseNames(zr)[pathBase(zf.Name)] > 1 {
+				return nil, fmt.Errorf("%w: %s shares its file name with another entry", ErrAmbiguousArchiveEntry, entry)
+			}
+			return zf, nil
+		}
+		return nil, fmt.Errorf("%w: %s", ErrArchiveEntryNotFound, entry)
+	}
+	entries := zipLogEntries(zr)
+	switch len(entries) {
+	case 0:
+		return nil, ErrArchiveEntryNotFound
+	case 1:
+		return entries[0], nil
+	default:
+		return nil, fmt.Errorf("%w: %d entries, select one explicitly", ErrAmbiguousArchiveEntry, len(entries))
+	}
+}
+
+// zipLogEntries returns the regular-file entries of zr whose base name
+// is an output_log file name.
+func zipLogEntries(zr *zip.Reader) []*zip.File {
+	var out []*zip.File
+	for _, zf := range zr.File {
+		if !zf.Mode().IsRegular() {
+			continue
+		}
+		if matched, _ := filepath.Match("output_log_*.txt", pathBase(zf.Name)); matched {
+			out = append(out, zf)
+		}
+	}
+	return out
+}
+
+// zipBaseNames counts the regular-file entries of zr by base name.
+func zipBaseNames(zr *zip.Reader) map[string]int {
+	counts := make(map[string]int, len(zr.File))
+	for _, zf := range zr.File {
+		if zf.Mode().IsRegular() {
+			counts[pathBase(zf.Name)]++
+		}
+	}
+	return counts
+}
+
+// listZipEntries opens a zip archive and lists its output_log entries.
+// Entries in different folders that share a base name are left out:
+// LogicalPath would give them all the same path, and so the same
+// SourceID.
+func (r Root) listZipEntries(archive string) ([]*zip.File, fs.FileInfo, error) {
+	f, info, err := r.OpenRegular(archive)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	zr, err := zip.NewReader(f, info.Size())
+	if err != nil {
+		return nil, nil, err
+	}
+	counts := zipBaseNames(zr)
+	var entries []*zip.File
+	for _, zf := range zipLogEntries(zr) {
+		if counts[pathBase(zf.Name)] == 1 {
+			entries = append(entries, zf)
+		}
+	}
+	return entries, info, nil
+}
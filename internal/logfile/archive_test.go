@@ -0,0 +1,143 @@
+package logfile
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeGzip(t *testing.T, path string, content string) {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeZip(t *testing.T, path string, entries map[string]string) {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range entries {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCompressionOf(t *testing.T) {
+	cases := map[string]Compression{
+		"output_log_2024-01-01_00-00-00.txt":    CompressionNone,
+		"output_log_2024-01-01_00-00-00.txt.gz": CompressionGzip,
+		"output_log_2024-01-01_00-00-00.TXT.GZ": CompressionGzip,
+		"logs.zip":                              CompressionZip,
+	}
+	for name, want := range cases {
+		if got := CompressionOf(name); got != want {
+			t.Errorf("CompressionOf(%q) = %d, want %d", name, got, want)
+		}
+	}
+}
+
+func TestLogicalPath(t *testing.T) {
+	dir := filepath.Join("logs", "archive")
+	if got, want := LogicalPath(filepath.Join(dir, "output_log_a.txt.gz"), ""), filepath.Join(dir, "output_log_a.txt"); got != want {
+		t.Errorf("gzip LogicalPath = %q, want %q", got, want)
+	}
+	if got, want := LogicalPath(filepath.Join(dir, "old.zip"), "nested/output_log_b.txt"), filepath.Join(dir, "output_log_b.txt"); got != want {
+		t.Errorf("zip LogicalPath = %q, want %q", got, want)
+	}
+}
+
+func TestOpenArchived_Gzip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "output_log_a.txt.gz")
+	writeGzip(t, path, "hello\n")
+
+	a, err := OpenArchived(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	data, err := io.ReadAll(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "hello\n" || a.Size != -1 {
+		t.Errorf("got %q size %d, want %q size -1", data, a.Size, "hello\n")
+	}
+}
+
+func TestOpenArchived_ZipEntrySelection(t *testing.T) {
+	dir := t.TempDir()
+	single := filepath.Join(dir, "single.zip")
+	writeZip(t, single, map[string]string{
+		"readme.md":            "ignored",
+		"output_log_a.txt":     "a\n",
+		"nested/notes.txt":     "ignored",
+		"nested/output_log_x/": "",
+	})
+	multi := filepath.Join(dir, "multi.zip")
+	writeZip(t, multi, map[string]string{
+		"output_log_a.txt":        "a\n",
+		"nested/output_log_b.txt": "bb\n",
+	})
+
+	a, err := OpenArchived(single, "")
+	if err != nil {
+		t.Fatalf("single entry: %v", err)
+	}
+	if a.Entry != "output_log_a.txt" || a.Size != 2 {
+		t.Errorf("single entry = %q size %d", a.Entry, a.Size)
+	}
+	a.Close()
+
+	if _, err := OpenArchived(multi, ""); !errors.Is(err, ErrAmbiguousArchiveEntry) {
+		t.Errorf("expected ErrAmbiguousArchiveEntry, got %v", err)
+	}
+
+	b, err := OpenArchived(multi, "nested/output_log_b.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(b)
+	b.Close()
+	if string(data) != "bb\n" {
+		t.Errorf("entry content = %q, want %q", data, "bb\n")
+	}
+
+	if _, err := OpenArchived(multi, "missing.txt"); !errors.Is(err, ErrArchiveEntryNotFound) {
+		t.Errorf("expected ErrArchiveEntryNotFound, got %v", err)
+	}
+
+	clash := filepath.Join(dir, "clash.zip")
+	writeZip(t, clash, map[string]string{
+		"pc1/output_log_a.txt": "a\n",
+		"pc2/output_log_a.txt": "a\n",
+	})
+	if _, err := OpenArchived(clash, "pc1/output_log_a.txt"); !errors.Is(err, ErrAmbiguousArchiveEntry) {
+		t.Errorf("same-named entry: expected ErrAmbiguousArchiveEntry, got %v", err)
+	}
+}
@@ -24,14 +24,46 @@ const filenameTimestampLayout = "2006-01-02_15-04-05"
 type LogFileInfo struct {
 	Path    string
 	ModTime time.Time
-	Name    string
+	// Size is the size in bytes of the file at Path, which for an
+	// archived log is the archive.
+	Size int64
+	// Name is the log file name. For archived logs it is the name of
+	// the original .txt file, not of the archive.
+	Name string
+	// Compression and Entry are set for archived logs listed by
+	// ListLogFilesWithArchives. Entry names the zip entry holding the
+	// log and is empty for gzip files.
+	Compression Compression
+	Entry       string
 }
 
+// LogicalPath returns the path of the original .txt file. It equals
+// Path unless the log is archived.
+func (i LogFileInfo) LogicalPath() string {
+	if i.Compression == CompressionNone {
+		return i.Path
+	}
+	return LogicalPath(i.Path, i.Entry)
+}
+
+// DefaultLogDirectory returns the VRChat log directory of the current
+// user: the LocalLow directory on Windows, and on Linux the one inside
+// the Steam Proton or Wine prefix VRChat runs in.
 func DefaultLogDirectory() (string, error) {
-	if runtime.GOOS != "windows" {
-		return "", fmt.Errorf("%w: auto-detection is only supported on Windows", ErrNoLogDirectory)
+	switch runtime.GOOS {
+	case "windows":
+		return windowsLogDirectory()
+	case "linux":
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrNoLogDirectory, err)
+		}
+		return linuxLogDirectory(home, os.Getenv)
 	}
+	return "", fmt.Errorf("%w: auto-detection is only supported on Windows and Linux", ErrNoLogDirectory)
+}
 
+func windowsLogDirectory() (string, error) {
 	localAppData := os.Getenv("LOCALAPPDATA")
 	if localAppData == "" {
 		userProfile := os.Getenv("USERPROFILE")
@@ -45,12 +77,24 @@ func DefaultLogDirectory() (string, error) {
 	}
 
 	localLow := filepath.Join(filepath.Dir(localAppData), "LocalLow")
+	if dir, ok := firstLogDirectory(vrchatCandidates(localLow)); ok {
+		return dir, nil
+	}
+	return "", fmt.Errorf("%w: VRChat log directory not found", ErrNoLogDirectory)
+}
 
-	candidates := []string{
+// vrchatCandidates returns the VRChat log directories to try inside a
+// LocalLow directory, in order of precedence.
+func vrchatCandidates(localLow string) []string {
+	return []string{
 		filepath.Join(localLow, "VRChat", "VRChat"),
 		filepath.Join(localLow, "VRChat", "vrchat"),
 	}
+}
 
+// firstLogDirectory returns the first candidate that is a directory,
+// with symlinks resolved.
+func firstLogDirectory(candidates []string) (string, bool) {
 	for _, dir := range candidates {
 		info, err := os.Stat(dir)
 		if err != nil || !info.IsDir() {
@@ -60,10 +104,9 @@ func DefaultLogDirectory() (string, error) {
 		if err != nil {
 			continue
 		}
-		return resolved, nil
+		return resolved, true
 	}
-
-	return "", fmt.Errorf("%w: VRChat log directory not found", ErrNoLogDirectory)
+	return "", false
 }
 
 // ListLogFiles lists VRChat output_log files, sorted oldest-first.
@@ -71,7 +114,7 @@ func DefaultLogDirectory() (string, error) {
 // skipped. Use ListLogFilesStrict when silent skips must not hide
 // permission, race, or non-regular-file errors on matched candidates.
 func ListLogFiles(dir string) ([]LogFileInfo, error) {
-	return listLogFiles(dir, false)
+	return Root{}.ListLogFiles(dir)
 }
 
 // ListLogFilesStrict lists VRChat output_log files, sorted oldest-first.
@@ -79,31 +122,77 @@ func ListLogFiles(dir string) ([]LogFileInfo, error) {
 // propagated as an error instead of being silently skipped, except when
 // the failure is ErrNotRegularFile (symlinks/directories are tolerated).
 func ListLogFilesStrict(dir string) ([]LogFileInfo, error) {
-	return listLogFiles(dir, true)
+	return Root{}.ListLogFilesStrict(dir)
 }
 
-func listLogFiles(dir string, strict bool) ([]LogFileInfo, error) {
-	absDir, err := filepath.Abs(dir)
+// ListLogFilesWithArchives is ListLogFilesStrict that additionally
+// lists gzip-compressed logs (output_log_*.txt.gz) and output_log
+// entries inside zip archives (*.zip). Zip archives that cannot be
+// opened or hold no output_log entry are skipped. An archived copy of a
+// log that also exists uncompressed, or in an earlier-listed archive, is
+// omitted. Follow does not use this: archived logs are never active.
+func ListLogFilesWithArchives(dir string) ([]LogFileInfo, error) {
+	return Root{}.ListLogFilesWithArchives(dir)
+}
+
+// ListLogFiles is ListLogFiles in r.
+func (r Root) ListLogFiles(dir string) ([]LogFileInfo, error) {
+	return r.listLogFiles(dir, listOptions{})
+}
+
+// ListLogFilesStrict is ListLogFilesStrict in r.
+func (r Root) ListLogFilesStrict(dir string) ([]LogFileInfo, error) {
+	return r.listLogFiles(dir, listOptions{strict: true})
+}
+
+// ListLogFilesWithArchives is ListLogFilesWithArchives in r.
+func (r Root) ListLogFilesWithArchives(dir string) ([]LogFileInfo, error) {
+	return r.listLogFiles(dir, listOptions{strict: true, archives: true})
+}
+
+type listOptions struct {
+	strict   bool
+	archives bool
+}
+
+type logCandidate struct {
+	path        string
+	name        string
+	compression Compression
+}
+
+func (r Root) listLogFiles(dir string, opts listOptions) ([]LogFileInfo, error) {
+	dir, err := r.Clean(dir)
 	if err != nil {
 		return nil, err
 	}
-	dir = filepath.Clean(absDir)
 
-	entries, err := os.ReadDir(dir)
+	entries, err := r.ReadDir(dir)
 	if err != nil {
 		return nil, err
 	}
 
-	var matches []string
+	var matches []logCandidate
 	for _, entry := range entries {
-		matched, err := filepath.Match("output_log_*.txt", entry.Name())
+		name := entry.Name()
+		matched, err := filepath.Match("output_log_*.txt", name)
 		if err != nil {
 			return nil, fmt.Errorf("matching log file name: %w", err)
 		}
-		if !matched {
+		if matched {
+			matches = append(matches, logCandidate{path: r.Join(dir, name), name: name})
+			continue
+		}
+		if !opts.archives {
 			continue
 		}
-		matches = append(matches, filepath.Join(dir, entry.Name()))
+		if gz, _ := filepath.Match("output_log_*.txt.gz", name); gz {
+			matches = append(matches, logCandidate{path: r.Join(dir, name), name: strings.TrimSuffix(name, ".gz"), compression: CompressionGzip})
+			continue
+		}
+		if CompressionOf(name) == CompressionZip {
+			matches = append(matches, logCandidate{path: r.Join(dir, name), name: name, compression: CompressionZip})
+		}
 	}
 
 	if len(matches) == 0 {
@@ -120,29 +209,78 @@ func listLogFiles(dir string, strict bool) ([]LogFileInfo, error) {
 		hasTimestamp bool
 	}
 	var files []sortableLogFile
+	add := func(info LogFileInfo) {
+		ts, ok := parseFilenameTimestamp(info.Name)
+		files = append(files, sortableLogFile{info: info, timestamp: ts, hasTimestamp: ok})
+	}
 	for _, m := range matches {
-		f, info, err := OpenRegular(m)
+		if m.compression == CompressionZip {
+			// Any zip in the directory is a candidate, so one that cannot
+			// be opened as a zip, or holds no log, is not an error.
+			zipEntries, info, err := r.listZipEntries(m.path)
+			if err != nil {
+				continue
+			}
+			for _, zf := range zipEntries {
+				modTime := zf.Modified
+				if modTime.IsZero() {
+					modTime = info.ModTime()
+				}
+				add(LogFileInfo{
+					Path:        m.path,
+					ModTime:     modTime,
+					Size:        info.Size(),
+					Name:        pathBase(zf.Name),
+					Compression: CompressionZip,
+					Entry:       zf.Name,
+				})
+			}
+			continue
+		}
+
+		f, info, err := r.Open(m.path)
 		if err != nil {
-			if strict && !errors.Is(err, ErrNotRegularFile) {
-				return nil, fmt.Errorf("open %s: %w", m, err)
+			if opts.strict && !errors.Is(err, ErrNotRegularFile) {
+				return nil, fmt.Errorf("open %s: %w", m.path, err)
 			}
 			continue
 		}
 		f.Close()
 
-		name := filepath.Base(m)
-		ts, ok := parseFilenameTimestamp(name)
-		files = append(files, sortableLogFile{
-			info: LogFileInfo{
-				Path:    m,
-				ModTime: info.ModTime(),
-				Name:    name,
-			},
-			timestamp:    ts,
-			hasTimestamp: ok,
+		add(LogFileInfo{
+			Path:        m.path,
+			ModTime:     info.ModTime(),
+			Size:        info.Size(),
+			Name:        m.name,
+			Compression: m.compression,
 		})
 	}
 
+	if opts.archives {
+		// Prefer the uncompressed file, then the first archived copy.
+		seen := make(map[string]bool, len(files))
+		for _, f := range files {
+			if f.info.Compression == CompressionNone {
+				seen[f.info.Name] = true
+			}
+		}
+		kept := files[:0]
+		for _, f := range files {
+			if f.info.Compression != CompressionNone {
+				if seen[f.info.Name] {
+					continue
+				}
+				seen[f.info.Name] = true
+			}
+			kept = append(kept, f)
+		}
+		files = kept
+
+		if len(files) > MaxFollowCandidateFiles {
+			return nil, fmt.Errorf("%w: found %d files (limit %d)", ErrTooManyLogFiles, len(files), MaxFollowCandidateFiles)
+		}
+	}
+
 	if len(files) == 0 {
 		return nil, ErrNoLogFiles
 	}
@@ -179,6 +317,13 @@ func FindLatestLogFile(dir string) (string, error) {
 }
 
 func parseFilenameTimestamp(name string) (time.Time, bool) {
+	return FilenameTimestamp(name, time.UTC)
+}
+
+// FilenameTimestamp returns the time in an output_log_<time>.txt file
+// name, read as a wall time in loc. ok is false if name has no such
+// time.
+func FilenameTimestamp(name string, loc *time.Location) (t time.Time, ok bool) {
 	const prefix = "output_log_"
 	const suffix = ".txt"
 
@@ -187,7 +332,7 @@ func parseFilenameTimestamp(name string) (time.Time, bool) {
 	}
 
 	tsStr := name[len(prefix) : len(name)-len(suffix)]
-	t, err := time.Parse(filenameTimestampLayout, tsStr)
+	t, err := time.ParseInLocation(filenameTimestampLayout, tsStr, loc)
 	if err != nil {
 		return time.Time{}, false
 	}
@@ -369,3 +369,101 @@ func TestParseFilenameTimestamp(t *testing.T) {
 		t.Error("expected parse to fail for non-matching prefix")
 	}
 }
+
+func TestFilenameTimestampLocation(t *testing.T) {
+	loc := time.FixedZone("JST", 9*60*60)
+	ts, ok := FilenameTimestamp("output_log_2026-08-18_16-06-48.txt", loc)
+	if !ok {
+		t.Fatal("expected parse to succeed")
+	}
+	if want := time.Date(2026, 8, 18, 16, 6, 48, 0, loc); !ts.Equal(want) || ts.Location() != loc {
+		t.Errorf("FilenameTimestamp = %v, want %v", ts, want)
+	}
+}
+
+func TestListLogFilesSize(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "output_log_2026-08-18_16-06-48.txt"), []byte("12345"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	files, err := ListLogFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Size != 5 {
+		t.Errorf("files = %+v, want one of size 5", files)
+	}
+}
+
+func TestListLogFilesWithArchives(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(dir, "output_log_2024-01-02_00-00-00.txt"), []byte("plain\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Archived duplicate of the plain file above: must be omitted.
+	writeGzip(t, filepath.Join(dir, "output_log_2024-01-02_00-00-00.txt.gz"), "plain\n")
+	writeGzip(t, filepath.Join(dir, "output_log_2024-01-01_00-00-00.txt.gz"), "gz\n")
+	writeZip(t, filepath.Join(dir, "old.zip"), map[string]string{
+		"output_log_2023-12-31_00-00-00.txt": "zip\n",
+		"readme.txt":                         "ignored",
+	})
+	// Zips that are not logs are skipped, even by a strict listing.
+	writeZip(t, filepath.Join(dir, "screenshots.zip"), map[string]string{"VRChat_2024-01-01.png": "png"})
+	if err := os.WriteFile(filepath.Join(dir, "broken.zip"), []byte("not a zip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	plain, err := ListLogFilesStrict(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plain) != 1 {
+		t.Fatalf("ListLogFilesStrict listed %d files, want only the plain one", len(plain))
+	}
+
+	files, err := ListLogFilesWithArchives(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("expected 3 files, got %d: %+v", len(files), files)
+	}
+
+	want := []struct {
+		name        string
+		compression Compression
+		entry       string
+	}{
+		{"output_log_2023-12-31_00-00-00.txt", CompressionZip, "output_log_2023-12-31_00-00-00.txt"},
+		{"output_log_2024-01-01_00-00-00.txt", CompressionGzip, ""},
+		{"output_log_2024-01-02_00-00-00.txt", CompressionNone, ""},
+	}
+	for i, w := range want {
+		f := files[i]
+		if f.Name != w.name || f.Compression != w.compression || f.Entry != w.entry {
+			t.Errorf("files[%d] = %+v, want name %q compression %d entry %q", i, f, w.name, w.compression, w.entry)
+		}
+		if got := f.LogicalPath(); got != filepath.Join(dir, w.name) {
+			t.Errorf("files[%d].LogicalPath() = %q, want %q", i, got, filepath.Join(dir, w.name))
+		}
+	}
+}
+
+func TestListLogFilesWithArchives_SameNamedZipEntries(t *testing.T) {
+	dir := t.TempDir()
+	writeZip(t, filepath.Join(dir, "logs.zip"), map[string]string{
+		"pc1/output_log_2024-01-01_00-00-00.txt": "pc1\n",
+		"pc2/output_log_2024-01-01_00-00-00.txt": "pc2\n",
+		"pc2/output_log_2024-01-02_00-00-00.txt": "unique\n",
+	})
+
+	// Both pc1 and pc2 entries would have the same LogicalPath.
+	files, err := ListLogFilesWithArchives(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Entry != "pc2/output_log_2024-01-02_00-00-00.txt" {
+		t.Errorf("files = %+v, want only the uniquely named entry", files)
+	}
+}
@@ -0,0 +1,305 @@
+package logfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// vrchatAppID is VRChat's Steam app ID, which names its Proton prefix.
+const vrchatAppID = "438100"
+
+// linuxLogDirectory finds the VRChat log directory of a Linux user whose
+// home directory is home. getenv reads the environment.
+//
+// Candidates are tried in order: the Proton prefix of VRChat in each
+// Steam library (the default libraries of the native, Flatpak and Snap
+// Steam installs, then the extra libraries listed in their
+// libraryfolders.vdf), then $WINEPREFIX and ~/.wine. Within a prefix the
+// rules are those of Windows: VRChat/VRChat before VRChat/vrchat, and
+// the first existing directory is returned with symlinks resolved.
+func linuxLogDirectory(home string, getenv func(string) string) (string, error) {
+	var candidates []string
+	for _, lib := range steamLibraries(home, getenv) {
+		users := filepath.Join(lib, "steamapps", "compatdata", vrchatAppID, "pfx", "drive_c", "users")
+		candidates = append(candidates, vrchatCandidates(filepath.Join(users, "steamuser", "AppData", "LocalLow"))...)
+	}
+	for _, prefix := range winePrefixes(home, getenv) {
+		for _, user := range wineUsers(prefix, getenv("USER")) {
+			localLow := filepath.Join(prefix, "drive_c", "users", user, "AppData", "LocalLow")
+			candidates = append(candidates, vrchatCandidates(localLow)...)
+		}
+	}
+
+	if dir, ok := firstLogDirectory(candidates); ok {
+		return dir, nil
+	}
+	return "", fmt.Errorf("%w: VRChat log directory not found in any Steam library or Wine prefix", ErrNoLogDirectory)
+}
+
+// steamRoots returns the directories a Steam client may be installed in,
+// in order of precedence.
+func steamRoots(home string, getenv func(string) string) []string {
+	dataHome := getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	return []string{
+		filepath.Join(dataHome, "Steam"),
+		filepath.Join(home, ".steam", "steam"),
+		filepath.Join(home, ".steam", "root"),
+		filepath.Join(home, ".var", "app", "com.valvesoftware.Steam", ".local", "share", "Steam"),
+		filepath.Join(home, ".var", "app", "com.valvesoftware.Steam", "data", "Steam"),
+		filepath.Join(home, "snap", "steam", "common", ".local", "share", "Steam"),
+	}
+}
+
+// steamLibraries returns the Steam library folders: every existing
+// Steam root, then the libraries their libraryfolders.vdf lists. Each
+// library appears once, at its first position, compared with symlinks
+// resolved.
+func steamLibraries(home string, getenv func(string) string) []string {
+	var libs []string
+	seen := make(map[string]bool)
+	add := func(dir string) {
+		resolved, err := filepath.EvalSymlinks(dir)
+		if err != nil || seen[resolved] {
+			return
+		}
+		seen[resolved] = true
+		libs = append(libs, dir)
+	}
+
+	roots := steamRoots(home, getenv)
+	for _, root := range roots {
+		add(root)
+	}
+	for _, root := range roots {
+		for _, name := range []string{
+			filepath.Join(root, "steamapps", "libraryfolders.vdf"),
+			filepath.Join(root, "config", "libraryfolders.vdf"),
+		} {
+			data, err := os.ReadFile(name)
+			if err != nil {
+				continue
+			}
+			paths, err := parseLibraryFolders(data)
+			if err != nil {
+				continue
+			}
+			for _, p := range paths {
+				add(p)
+			}
+		}
+	}
+	return libs
+}
+
+// winePrefixes returns the Wine prefixes to search: $WINEPREFIX, then
+// the default ~/.wine.
+func winePrefixes(home string, getenv func(string) string) []string {
+	var prefixes []string
+	if p := getenv("WINEPREFIX"); p != "" {
+		prefixes = append(prefixes, p)
+	}
+	return append(prefixes, filepath.Join(home, ".wine"))
+}
+
+// wineUsers returns the user directories of a Wine prefix: the current
+// user's first, then the others by name. Public is not a user.
+func wineUsers(prefix, current string) []string {
+	var users []string
+	if current != "" {
+		users = append(users, current)
+	}
+	entries, err := os.ReadDir(filepath.Join(prefix, "drive_c", "users"))
+	if err != nil {
+		return users
+	}
+	var others []string
+	for _, e := range entries {
+		if e.IsDir() && e.Name() != current && e.Name() != "Public" {
+			others = append(others, e.Name())
+		}
+	}
+	sort.Strings(others)
+	return append(users, others...)
+}
+
+// parseLibraryFolders returns the library paths in a Steam
+// libraryfolders.vdf, in file order. It reads both the current format,
+// where each numbered entry is a block with a "path" key, and the older
+// one, where the numbered key maps to the path directly.
+func parseLibraryFolders(data []byte) ([]string, error) {
+	root, err := parseVDF(string(data))
+	if err != nil {
+		return nil, err
+	}
+	var folders []vdfNode
+	for _, n := range root {
+		if strings.EqualFold(n.key, "libraryfolders") {
+			folders = n.children
+			break
+		}
+	}
+
+	var paths []string
+	for _, n := range folders {
+		if !isDigits(n.key) {
+			continue
+		}
+		if n.children == nil {
+			paths = append(paths, n.value)
+			continue
+		}
+		for _, c := range n.children {
+			if strings.EqualFold(c.key, "path") && c.children == nil {
+				paths = append(paths, c.value)
+				break
+			}
+		}
+	}
+	return paths, nil
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// vdfNode is a key of a Valve KeyValues (VDF) text document, with either
+// a string value or, if children is non-nil, a block.
+type vdfNode struct {
+	key      string
+	value    string
+	children []vdfNode
+}
+
+// parseVDF parses the KeyValues text format Steam uses for its
+// configuration files: quoted (or bare) keys followed by a quoted value
+// or a braced block, with // comments.
+func parseVDF(s string) ([]vdfNode, error) {
+	p := vdfParser{s: s}
+	nodes, err := p.block(0)
+	if err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+type vdfParser struct {
+	s   string
+	pos int
+}
+
+// block parses keys until a closing brace, or the end of input at
+// depth 0.
+func (p *vdfParser) block(depth int) ([]vdfNode, error) {
+	nodes := []vdfNode{}
+	for {
+		tok, quoted, err := p.token()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case tok == "" && !quoted:
+			if depth > 0 {
+				return nil, fmt.Errorf("vdf: unexpected end of input")
+			}
+			return nodes, nil
+		case tok == "}" && !quoted:
+			if depth == 0 {
+				return nil, fmt.Errorf("vdf: unexpected '}' at offset %d", p.pos-1)
+			}
+			return nodes, nil
+		case tok == "{" && !quoted:
+			return nil, fmt.Errorf("vdf: unexpected '{' at offset %d", p.pos-1)
+		}
+
+		key := tok
+		val, quoted, err := p.token()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case val == "{" && !quoted:
+			children, err := p.block(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, vdfNode{key: key, children: children})
+		case (val == "" || val == "}") && !quoted:
+			return nil, fmt.Errorf("vdf: key %q has no value", key)
+		default:
+			nodes = append(nodes, vdfNode{key: key, value: val})
+		}
+	}
+}
+
+// token returns the next string, brace, or "" at the end of input.
+// quoted reports whether it was a quoted string.
+func (p *vdfParser) token() (tok string, quoted bool, err error) {
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			p.pos++
+		case strings.HasPrefix(p.s[p.pos:], "//"):
+			if i := strings.IndexByte(p.s[p.pos:], '\n'); i >= 0 {
+				p.pos += i + 1
+			} else {
+				p.pos = len(p.s)
+			}
+		case c == '{' || c == '}':
+			p.pos++
+			return string(c), false, nil
+		case c == '"':
+			return p.quoted()
+		default:
+			start := p.pos
+			for p.pos < len(p.s) && !strings.ContainsRune(" \t\r\n{}\"", rune(p.s[p.pos])) {
+				p.pos++
+			}
+			return p.s[start:p.pos], false, nil
+		}
+	}
+	return "", false, nil
+}
+
+func (p *vdfParser) quoted() (string, bool, error) {
+	start := p.pos
+	p.pos++
+	var b strings.Builder
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		p.pos++
+		switch c {
+		case '"':
+			return b.String(), true, nil
+		case '\\':
+			if p.pos < len(p.s) {
+				switch e := p.s[p.pos]; e {
+				case 'n':
+					b.WriteByte('\n')
+				case 't':
+					b.WriteByte('\t')
+				default:
+					b.WriteByte(e)
+				}
+				p.pos++
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", false, fmt.Errorf("vdf: unterminated string at offset %d", start)
+}
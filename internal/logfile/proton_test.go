@@ -0,0 +1,176 @@
+package logfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func mkdirAll(t *testing.T, dir string) string {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func protonLocalLow(lib string) string {
+	return filepath.Join(lib, "steamapps", "compatdata", vrchatAppID, "pfx", "drive_c", "users", "steamuser", "AppData", "LocalLow")
+}
+
+func envOf(vars map[string]string) func(string) string {
+	return func(k string) string { return vars[k] }
+}
+
+func TestParseLibraryFolders(t *testing.T) {
+	current := `"libraryfolders"
+{
+	// written by Steam
+	"0"
+	{
+		"path"		"/home/me/.local/share/Steam"
+		"label"		""
+		"apps"
+		{
+			"438100"		"12345"
+		}
+	}
+	"1"
+	{
+		"path"		"/mnt/games/Steam \"Library\""
+	}
+	"contentstatsid"		"-123"
+}
+`
+	got, err := parseLibraryFolders([]byte(current))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"/home/me/.local/share/Steam", `/mnt/games/Steam "Library"`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("current format = %q, want %q", got, want)
+	}
+
+	legacy := `"LibraryFolders" { "TimeNextStatsReport" "1" "ContentStatsID" "2" "1" "D:\\SteamLibrary" }`
+	got, err = parseLibraryFolders([]byte(legacy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{`D:\SteamLibrary`}; !reflect.DeepEqual(got, want) {
+		t.Errorf("legacy format = %q, want %q", got, want)
+	}
+
+	for _, bad := range []string{`"libraryfolders" {`, `"libraryfolders" { "0" }`, `"a" "unterminated`, `}`} {
+		if _, err := parseLibraryFolders([]byte(bad)); err == nil {
+			t.Errorf("parseLibraryFolders(%q) should fail", bad)
+		}
+	}
+}
+
+func TestLinuxLogDirectory_ExtraLibrary(t *testing.T) {
+	home := t.TempDir()
+	extra := t.TempDir()
+	steam := mkdirAll(t, filepath.Join(home, ".local", "share", "Steam", "steamapps"))
+	vdf := `"libraryfolders" { "0" { "path" "` + filepath.Dir(steam) + `" } "1" { "path" "` + extra + `" } }`
+	if err := os.WriteFile(filepath.Join(steam, "libraryfolders.vdf"), []byte(vdf), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	want := mkdirAll(t, filepath.Join(protonLocalLow(extra), "VRChat", "VRChat"))
+
+	got, err := linuxLogDirectory(home, envOf(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != resolvedPath(t, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// The default library takes precedence over extra ones.
+	first := mkdirAll(t, filepath.Join(protonLocalLow(filepath.Dir(steam)), "VRChat", "vrchat"))
+	if got, _ := linuxLogDirectory(home, envOf(nil)); got != resolvedPath(t, first) {
+		t.Errorf("got %q, want the default library's %q", got, first)
+	}
+}
+
+func TestLinuxLogDirectory_XDGDataHome(t *testing.T) {
+	home := t.TempDir()
+	data := t.TempDir()
+	want := mkdirAll(t, filepath.Join(protonLocalLow(filepath.Join(data, "Steam")), "VRChat", "VRChat"))
+
+	got, err := linuxLogDirectory(home, envOf(map[string]string{"XDG_DATA_HOME": data}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != resolvedPath(t, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestLinuxLogDirectory_ResolvesSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require privileges on windows")
+	}
+	home := t.TempDir()
+	target := mkdirAll(t, filepath.Join(t.TempDir(), "logs"))
+	localLow := mkdirAll(t, filepath.Join(protonLocalLow(filepath.Join(home, ".local", "share", "Steam")), "VRChat"))
+	if err := os.Symlink(target, filepath.Join(localLow, "VRChat")); err != nil {
+		t.Fatal(err)
+	}
+	// ~/.steam/steam usually links to the same install.
+	mkdirAll(t, filepath.Join(home, ".steam"))
+	if err := os.Symlink(filepath.Join(home, ".local", "share", "Steam"), filepath.Join(home, ".steam", "steam")); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := linuxLogDirectory(home, envOf(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != resolvedPath(t, target) {
+		t.Errorf("got %q, want the resolved %q", got, target)
+	}
+	if libs := steamLibraries(home, envOf(nil)); len(libs) != 1 {
+		t.Errorf("libraries = %q, want the linked install once", libs)
+	}
+}
+
+func TestLinuxLogDirectory_WinePrefix(t *testing.T) {
+	home := t.TempDir()
+	prefix := t.TempDir()
+	mkdirAll(t, filepath.Join(prefix, "drive_c", "users", "Public", "AppData", "LocalLow", "VRChat", "VRChat"))
+	want := mkdirAll(t, filepath.Join(prefix, "drive_c", "users", "alice", "AppData", "LocalLow", "VRChat", "VRChat"))
+
+	got, err := linuxLogDirectory(home, envOf(map[string]string{"WINEPREFIX": prefix, "USER": "bob"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != resolvedPath(t, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// A Proton prefix is preferred over a Wine prefix.
+	proton := mkdirAll(t, filepath.Join(protonLocalLow(filepath.Join(home, ".steam", "root")), "VRChat", "VRChat"))
+	if got, _ := linuxLogDirectory(home, envOf(map[string]string{"WINEPREFIX": prefix})); got != resolvedPath(t, proton) {
+		t.Errorf("got %q, want the Proton prefix's %q", got, proton)
+	}
+}
+
+func TestLinuxLogDirectory_NotFound(t *testing.T) {
+	home := t.TempDir()
+	mkdirAll(t, filepath.Join(home, ".local", "share", "Steam", "steamapps"))
+	if _, err := linuxLogDirectory(home, envOf(nil)); !errors.Is(err, ErrNoLogDirectory) {
+		t.Errorf("error = %v, want ErrNoLogDirectory", err)
+	}
+}
+
+func resolvedPath(t *testing.T, p string) string {
+	t.Helper()
+	r, err := filepath.EvalSymlinks(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
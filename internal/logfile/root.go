@@ -0,0 +1,173 @@
+package logfile
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// ErrNoRandomAccess is returned when a file opened from an fs.FS does not
+// implement io.ReaderAt and io.Seeker.
+var ErrNoRandomAccess = errors.New("file does not support random access")
+
+// Root is the file system log files are listed and opened in. The zero
+// Root is the host file system, addressed by native paths. FSRoot
+// addresses an fs.FS by the slash-separated names fs.FS uses.
+type Root struct {
+	fsys fs.FS
+}
+
+// FSRoot returns a Root for fsys. A nil fsys is the host file system.
+func FSRoot(fsys fs.FS) Root {
+	return Root{fsys: fsys}
+}
+
+// IsHost reports whether r is the host file system.
+func (r Root) IsHost() bool {
+	return r.fsys == nil
+}
+
+// CanStat reports whether sizes in r can be polled without opening
+// files: always on the host, and in an fs.FS that implements fs.StatFS.
+func (r Root) CanStat() bool {
+	if r.fsys == nil {
+		return true
+	}
+	_, ok := r.fsys.(fs.StatFS)
+	return ok
+}
+
+// File is an open regular log file.
+type File interface {
+	io.Reader
+	io.ReaderAt
+	io.Seeker
+	io.Closer
+	Stat() (fs.FileInfo, error)
+}
+
+// Clean returns the canonical form of name: absolute and clean on the
+// host, clean and valid in an fs.FS (an empty name is the FS root ".").
+func (r Root) Clean(name string) (string, error) {
+	if r.fsys == nil {
+		abs, err := filepath.Abs(name)
+		if err != nil {
+			return "", err
+		}
+		return filepath.Clean(abs), nil
+	}
+	name = path.Clean(name)
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	return name, nil
+}
+
+// Join joins path elements with the separator of r.
+func (r Root) Join(elem ...string) string {
+	if r.fsys == nil {
+		return filepath.Join(elem...)
+	}
+	return path.Join(elem...)
+}
+
+// ReadDir reads the entries of dir.
+func (r Root) ReadDir(dir string) ([]fs.DirEntry, error) {
+	if r.fsys == nil {
+		return os.ReadDir(dir)
+	}
+	return fs.ReadDir(r.fsys, dir)
+}
+
+// Stat returns the FileInfo of name, following symlinks.
+func (r Root) Stat(name string) (fs.FileInfo, error) {
+	if r.fsys == nil {
+		return os.Stat(name)
+	}
+	return fs.Stat(r.fsys, name)
+}
+
+// OpenRegular opens name as OpenRegular does on the host. In an fs.FS the
+// symlink check applies if the FS implements fs.ReadLinkFS, and the
+// same-file check if it reports host file information (as os.DirFS
+// does). The file must support random access.
+func (r Root) OpenRegular(name string) (File, fs.FileInfo, error) {
+	f, info, err := r.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	rf, ok := f.(File)
+	if !ok {
+		f.Close()
+		return nil, nil, fmt.Errorf("%s: %w", name, ErrNoRandomAccess)
+	}
+	return rf, info, nil
+}
+
+// Open opens name with the checks of OpenRegular, but returns files of
+// an fs.FS that only support sequential reads as they are. Files of the
+// host are *os.File values, which support random access.
+func (r Root) Open(name string) (fs.File, fs.FileInfo, error) {
+	if r.fsys == nil {
+		f, info, err := OpenRegular(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		return f, info, nil
+	}
+
+	lstatInfo, err := fs.Lstat(r.fsys, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !lstatInfo.Mode().IsRegular() {
+		return nil, nil, ErrNotRegularFile
+	}
+
+	f, err := r.fsys.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	fdInfo, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	if !fdInfo.Mode().IsRegular() {
+		f.Close()
+		return nil, nil, ErrNotRegularFile
+	}
+	if isHostInfo(lstatInfo) && isHostInfo(fdInfo) && !os.SameFile(lstatInfo, fdInfo) {
+		f.Close()
+		return nil, nil, ErrFileChangedDuringOpen
+	}
+	return f, fdInfo, nil
+}
+
+// isHostInfo reports whether info came from the os package, which is
+// the only case os.SameFile can compare.
+func isHostInfo(info fs.FileInfo) bool {
+	return os.SameFile(info, info)
+}
+
+// SourceID returns the source ID of the log at name. On the host it is
+// derived from the normalized absolute path. In an fs.FS it is derived
+// from the name within the FS, so it is stable across runs over the same
+// tree, and equal for equally named files of different trees.
+func (r Root) SourceID(name string) (string, error) {
+	if r.fsys == nil {
+		return SourceID(name)
+	}
+	name, err := r.Clean(name)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256([]byte(name))
+	return hex.EncodeToString(h[:]), nil
+}
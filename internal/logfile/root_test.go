@@ -0,0 +1,157 @@
+package logfile
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"testing/fstest"
+)
+
+func TestRootFS_ListLogFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"logs/output_log_2024-01-02_00-00-00.txt": {Data: []byte("b\n")},
+		"logs/output_log_2024-01-01_00-00-00.txt": {Data: []byte("a\n")},
+		"logs/unrelated.txt":                      {Data: []byte("x\n")},
+		"logs/output_log_2024-01-03_00-00-00.txt": {Mode: fs.ModeDir},
+	}
+	files, err := FSRoot(fsys).ListLogFilesStrict("logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"logs/output_log_2024-01-01_00-00-00.txt", "logs/output_log_2024-01-02_00-00-00.txt"}
+	if len(files) != len(want) {
+		t.Fatalf("got %+v, want %v", files, want)
+	}
+	for i, w := range want {
+		if files[i].Path != w {
+			t.Errorf("files[%d].Path = %q, want %q", i, files[i].Path, w)
+		}
+	}
+}
+
+func TestRootFS_ListArchives(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "old.zip")
+	writeZip(t, zipPath, map[string]string{"output_log_2024-01-01_00-00-00.txt": "a\n"})
+	data, err := os.ReadFile(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := FSRoot(fstest.MapFS{"logs/old.zip": {Data: data}})
+
+	files, err := root.ListLogFilesWithArchives("logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].LogicalPath() != "logs/output_log_2024-01-01_00-00-00.txt" {
+		t.Fatalf("files = %+v", files)
+	}
+	a, err := root.OpenArchived(files[0].Path, files[0].Entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	if got, _ := io.ReadAll(a); string(got) != "a\n" {
+		t.Errorf("entry content = %q", got)
+	}
+}
+
+func TestRootFS_Clean(t *testing.T) {
+	root := FSRoot(fstest.MapFS{})
+	if got, err := root.Clean(""); err != nil || got != "." {
+		t.Errorf("Clean(\"\") = %q, %v; want \".\"", got, err)
+	}
+	if got, err := root.Clean("logs/./a/../b"); err != nil || got != "logs/b" {
+		t.Errorf("Clean = %q, %v; want logs/b", got, err)
+	}
+	for _, bad := range []string{"../logs", "/abs"} {
+		if _, err := root.Clean(bad); !errors.Is(err, fs.ErrInvalid) {
+			t.Errorf("Clean(%q) error = %v, want fs.ErrInvalid", bad, err)
+		}
+	}
+}
+
+func TestRootFS_SourceID(t *testing.T) {
+	root := FSRoot(fstest.MapFS{})
+	a, err := root.SourceID("logs/output_log_a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := root.SourceID("logs/./output_log_a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	host, err := SourceID("logs/output_log_a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != b {
+		t.Error("SourceID should not depend on name spelling")
+	}
+	if a == host {
+		t.Error("FS SourceID should differ from the host path's")
+	}
+}
+
+func TestRootFS_OpenRegularRejectsSymlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require privileges on windows")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "target.txt"), []byte("x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("target.txt", filepath.Join(dir, "output_log_a.txt")); err != nil {
+		t.Fatal(err)
+	}
+	root := FSRoot(os.DirFS(dir))
+
+	if _, _, err := root.OpenRegular("output_log_a.txt"); !errors.Is(err, ErrNotRegularFile) {
+		t.Errorf("symlink error = %v, want ErrNotRegularFile", err)
+	}
+	f, info, err := root.OpenRegular("target.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if info.Size() != 2 {
+		t.Errorf("size = %d, want 2", info.Size())
+	}
+}
+
+// streamFS serves files that only support sequential reads.
+type streamFS struct{ fstest.MapFS }
+
+type streamFile struct{ fs.File }
+
+func (s streamFS) Open(name string) (fs.File, error) {
+	f, err := s.MapFS.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return streamFile{f}, nil
+}
+
+func TestRootFS_OpenRegularNeedsRandomAccess(t *testing.T) {
+	root := FSRoot(streamFS{fstest.MapFS{"output_log_a.txt": {Data: []byte("x\n")}}})
+	if _, _, err := root.OpenRegular("output_log_a.txt"); !errors.Is(err, ErrNoRandomAccess) {
+		t.Errorf("error = %v, want ErrNoRandomAccess", err)
+	}
+	f, info, err := root.Open("output_log_a.txt")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	f.Close()
+	if info.Size() != 2 {
+		t.Errorf("size = %d, want 2", info.Size())
+	}
+	if FSRoot(struct{ fs.FS }{fstest.MapFS{}}).CanStat() {
+		t.Error("a plain fs.FS cannot stat")
+	}
+	if !FSRoot(fstest.MapFS{}).CanStat() || !(Root{}).CanStat() {
+		t.Error("MapFS and the host can stat")
+	}
+}
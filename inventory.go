@@ -0,0 +1,207 @@
+package vrclog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sync"
+	"time"
+
+	"github.com/vrclog/vrclog-go/internal/logfile"
+)
+
+type ListLogFilesConfig struct {
+	Directory string
+	// FS, when set, is the file system Directory names a directory in;
+	// see ReadFileConfig.FS. An empty Directory is then the root of FS.
+	FS fs.FS
+
+	// IncludeArchives also lists gzip-compressed logs and output_log
+	// entries of zip archives in the directory; see ReadFile.
+	IncludeArchives bool
+
+	// Location is the time zone file name and header wall times are
+	// decoded in; see ReadFileConfig.Location.
+	Location *time.Location
+
+	// Header decodes line headers for LogFile.Stats; see
+	// ReadFileConfig.Header.
+	Header HeaderDecoder
+}
+
+// LogFile is one log in a ListLogFiles inventory. Its fields come from
+// the directory listing; LogFile.Stats reads the log for the rest.
+type LogFile struct {
+	SourceID SourceID `json:"source_id"`
+	// Path is the file the log is stored in, as in Record.Path. Entry
+	// names the log inside a zip archive and is empty otherwise.
+	Path  string `json:"path"`
+	Entry string `json:"entry,omitempty"`
+	// Name is the log file name; for an archived log, that of the
+	// original .txt file.
+	Name     string `json:"name"`
+	Archived bool   `json:"archived,omitempty"`
+
+	// Size is the size in bytes of the file at Path, which for an
+	// archived log is the compressed archive.
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	// Started is the time in the file name, when VRChat created the log.
+	// It is zero if the name has none.
+	Started time.Time `json:"started,omitzero"`
+
+	// Active is set on the newest uncompressed log, the one VRChat may
+	// still be writing to and Follow follows.
+	Active bool `json:"active"`
+
+	root logfile.Root
+	info logfile.LogFileInfo
+	opts recordOptions
+
+	mu    sync.Mutex
+	stats *LogFileStats
+}
+
+// LogFileStats are the LogFile fields that take a read of the whole log.
+type LogFileStats struct {
+	// Lines is the number of lines, counting an unterminated final one.
+	Lines uint64 `json:"lines"`
+	// FirstTime and LastTime are the times of the first and last lines
+	// with a decodable header. They are zero if there are none.
+	FirstTime time.Time `json:"first_time,omitzero"`
+	LastTime  time.Time `json:"last_time,omitzero"`
+	// Complete reports whether the log looks finished: it is not Active
+	// and its last line is terminated.
+	Complete bool `json:"complete"`
+}
+
+// ListLogFiles lists the VRChat output_log files in a directory, oldest
+// first, in the same order ReadDirectory reads them. An empty Directory
+// uses DefaultLogDirectory; a directory without log files gives an
+// empty list.
+func ListLogFiles(cfg ListLogFilesConfig) ([]*LogFile, error) {
+	root := logfile.FSRoot(cfg.FS)
+	dir := cfg.Directory
+	if dir == "" && root.IsHost() {
+		d, err := DefaultLogDirectory()
+		if err != nil {
+			return nil, err
+		}
+		dir = d
+	}
+	dir, err := root.Clean(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	list := root.ListLogFilesStrict
+	if cfg.IncludeArchives {
+		list = root.ListLogFilesWithArchives
+	}
+	infos, err := list(dir)
+	if err != nil {
+		if errors.Is(err, logfile.ErrNoLogFiles) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("list log files: %w", err)
+	}
+
+	loc := cfg.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	active := -1
+	for i := len(infos) - 1; i >= 0; i-- {
+		if infos[i].Compression == logfile.CompressionNone {
+			active = i
+			break
+		}
+	}
+	files := make([]*LogFile, len(infos))
+	for i, info := range infos {
+		id, err := root.SourceID(info.LogicalPath())
+		if err != nil {
+			return nil, err
+		}
+		started, _ := logfile.FilenameTimestamp(info.Name, loc)
+		files[i] = &LogFile{
+			SourceID: SourceID(id),
+			Path:     info.Path,
+			Entry:    info.Entry,
+			Name:     info.Name,
+			Archived: info.Compression != logfile.CompressionNone,
+			Size:     info.Size,
+			ModTime:  info.ModTime,
+			Started:  started,
+			Active:   i == active,
+			root:     root,
+			info:     info,
+			opts:     recordOptions{loc: cfg.Location, headers: cfg.Header},
+		}
+	}
+	return files, nil
+}
+
+// Stats reads the log up to the end it has when opened and returns its
+// LogFileStats. The result of the first successful call is kept and
+// returned by later ones.
+func (f *LogFile) Stats(ctx context.Context) (LogFileStats, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.stats != nil {
+		return *f.stats, nil
+	}
+	stats, err := f.readStats(ctx)
+	if err != nil {
+		return LogFileStats{}, err
+	}
+	f.stats = &stats
+	return stats, nil
+}
+
+func (f *LogFile) readStats(ctx context.Context) (LogFileStats, error) {
+	src, err := openLogSource(f.root, f.info.Path, f.info.Entry, 0)
+	if err != nil {
+		return LogFileStats{}, fmt.Errorf("open %s: %w", f.Path, err)
+	}
+	defer src.Close()
+
+	var r io.Reader = src
+	if src.size >= 0 {
+		r = io.LimitReader(src, src.size)
+	}
+
+	var stats LogFileStats
+	terminated := true
+	// Only the header times are needed, so lines are not hashed into
+	// records.
+	lr := newLineReader(r, 0, 1, f.opts)
+	lr.unhashed = true
+	for {
+		if err := ctx.Err(); err != nil {
+			return LogFileStats{}, err
+		}
+		raw, _, _, _, _, term, _, err := lr.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return LogFileStats{}, err
+		}
+		stats.Lines++
+		if h, ok := f.opts.decodeHeader(string(raw)); ok && !h.Time.IsZero() {
+			if stats.FirstTime.IsZero() {
+				stats.FirstTime = h.Time
+			}
+			stats.LastTime = h.Time
+		}
+		terminated = term
+		if !term {
+			break
+		}
+	}
+	stats.Complete = !f.Active && terminated
+	return stats, nil
+}
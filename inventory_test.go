@@ -0,0 +1,119 @@
+package vrclog
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestListLogFiles(t *testing.T) {
+	dir := t.TempDir()
+	first := logLine("2024.01.01 00:00:01", "a") + "  continuation\n" + logLine("2024.01.01 00:00:05", "b")
+	writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt", first)
+	crashed := logLine("2024.01.02 00:00:01", "c") + "2024.01.02 00:00:02 Log        -  cut"
+	writeLogFile(t, dir, "output_log_2024-01-02_00-00-00.txt", crashed)
+	writeLogFile(t, dir, "output_log_2024-01-03_00-00-00.txt", logLine("2024.01.03 00:00:01", "d"))
+
+	loc := time.FixedZone("JST", 9*60*60)
+	files, err := ListLogFiles(ListLogFilesConfig{Directory: dir, Location: loc})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("got %d files, want 3", len(files))
+	}
+
+	f := files[0]
+	wantID, err := SourceIDForPath(filepath.Join(dir, "output_log_2024-01-01_00-00-00.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.SourceID != wantID || f.Name != "output_log_2024-01-01_00-00-00.txt" || f.Size != int64(len(first)) {
+		t.Errorf("file = %+v", f)
+	}
+	if want := time.Date(2024, 1, 1, 0, 0, 0, 0, loc); !f.Started.Equal(want) {
+		t.Errorf("Started = %v, want %v", f.Started, want)
+	}
+	if f.Active || files[1].Active || !files[2].Active {
+		t.Errorf("active = %v %v %v, want only the newest", f.Active, files[1].Active, files[2].Active)
+	}
+
+	want := []LogFileStats{
+		{Lines: 3, FirstTime: time.Date(2024, 1, 1, 0, 0, 1, 0, loc), LastTime: time.Date(2024, 1, 1, 0, 0, 5, 0, loc), Complete: true},
+		{Lines: 2, FirstTime: time.Date(2024, 1, 2, 0, 0, 1, 0, loc), LastTime: time.Date(2024, 1, 2, 0, 0, 2, 0, loc)},
+		{Lines: 1, FirstTime: time.Date(2024, 1, 3, 0, 0, 1, 0, loc), LastTime: time.Date(2024, 1, 3, 0, 0, 1, 0, loc)},
+	}
+	for i, f := range files {
+		stats, err := f.Stats(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stats.Lines != want[i].Lines || !stats.FirstTime.Equal(want[i].FirstTime) || !stats.LastTime.Equal(want[i].LastTime) || stats.Complete != want[i].Complete {
+			t.Errorf("file %d stats = %+v, want %+v", i, stats, want[i])
+		}
+	}
+}
+
+func TestLogFileStatsCached(t *testing.T) {
+	dir := t.TempDir()
+	path := writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt", logLine("2024.01.01 00:00:01", "a"))
+
+	files, err := ListLogFiles(ListLogFilesConfig{Directory: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := files[0].Stats(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendToFile(t, path, logLine("2024.01.01 00:00:02", "b"))
+	again, err := files[0].Stats(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != again || again.Lines != 1 {
+		t.Errorf("stats = %+v, then %+v; want the first result kept", first, again)
+	}
+}
+
+func TestListLogFiles_ArchivesAndFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"logs/output_log_2024-01-01_00-00-00.txt.gz": {Data: gzipBytes(t, archiveTestContent)},
+		"logs/output_log_2024-01-02_00-00-00.txt":    {Data: []byte(logLine("2024.01.02 00:00:01", "live"))},
+	}
+	files, err := ListLogFiles(ListLogFilesConfig{FS: fsys, Directory: "logs", IncludeArchives: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("got %d files, want 2", len(files))
+	}
+	archived := files[0]
+	if !archived.Archived || archived.Active || archived.Name != "output_log_2024-01-01_00-00-00.txt" {
+		t.Errorf("archived file = %+v", archived)
+	}
+	stats, err := archived.Stats(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Lines != 3 || stats.Complete {
+		t.Errorf("archived stats = %+v, want 3 lines, incomplete", stats)
+	}
+	if !files[1].Active {
+		t.Error("uncompressed file should be active")
+	}
+}
+
+func TestListLogFiles_Empty(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "other.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	files, err := ListLogFiles(ListLogFilesConfig{Directory: dir})
+	if err != nil || len(files) != 0 {
+		t.Errorf("ListLogFiles = %v, %v; want no files", files, err)
+	}
+}
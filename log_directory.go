@@ -0,0 +1,202 @@
+package vrclog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vrclog/vrclog-go/internal/logfile"
+)
+
+// LogDirectoryEnv is the environment variable that overrides the log
+// directory.
+const LogDirectoryEnv = "VRCLOG_DIR"
+
+// LogDirectoryRule names a rule of the log directory resolution chain.
+type LogDirectoryRule string
+
+const (
+	// LogDirectoryFromEnv takes the directory from VRCLOG_DIR.
+	LogDirectoryFromEnv LogDirectoryRule = "env"
+	// LogDirectoryFromConfig takes the directory from the log_directory
+	// key of the user config file; see LogDirectoryConfigPath.
+	LogDirectoryFromConfig LogDirectoryRule = "config"
+	// LogDirectoryFromDiscovery finds VRChat's own log directory on
+	// Windows, or in a Steam Proton or Wine prefix on Linux.
+	LogDirectoryFromDiscovery LogDirectoryRule = "discovery"
+)
+
+// LogDirectoryCandidate is one rule ResolveLogDirectory tried.
+type LogDirectoryCandidate struct {
+	Rule LogDirectoryRule `json:"rule"`
+	// Origin is where the rule looked: the environment variable or the
+	// config file. It is empty for discovery.
+	Origin string `json:"origin,omitempty"`
+	// Dir is the directory the rule named, if it named one.
+	Dir string `json:"dir,omitempty"`
+	// Rejected says why the candidate was not used. It is empty for the
+	// candidate that was.
+	Rejected string `json:"rejected,omitempty"`
+}
+
+// LogDirectoryResolution reports how ResolveLogDirectory picked a log
+// directory.
+type LogDirectoryResolution struct {
+	// Dir is the resolved directory, and Rule the rule that named it.
+	// Both are empty if no rule succeeded.
+	Dir  string           `json:"dir,omitempty"`
+	Rule LogDirectoryRule `json:"rule,omitempty"`
+	// Candidates lists the rules tried, in order, up to the one that
+	// succeeded or failed.
+	Candidates []LogDirectoryCandidate `json:"candidates"`
+}
+
+// logDirectoryConfig is the user config file.
+type logDirectoryConfig struct {
+	LogDirectory string `json:"log_directory"`
+}
+
+// LogDirectoryConfigPath returns the path of the user config file:
+// vrclog/config.json in os.UserConfigDir. It is a JSON object whose
+// "log_directory" key names an absolute log directory.
+func LogDirectoryConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "vrclog", "config.json"), nil
+}
+
+// ResolveLogDirectory picks the log directory every default-directory
+// caller (Follow, ReadDirectory, CaptureLogSnapshot and the CLI) uses:
+// the first of VRCLOG_DIR, the user config file and platform discovery
+// that names a directory. A rule that names none (VRCLOG_DIR empty, no
+// config file or no log_directory key) is passed over, but one that
+// names something other than an existing directory, or a config file
+// that cannot be read, is an error rather than a reason to fall back
+// to another directory. The resolution lists every rule tried and why
+// it was rejected, and is returned with the error, which wraps
+// ErrNoLogDirectory.
+func ResolveLogDirectory() (LogDirectoryResolution, error) {
+	return resolveLogDirectory(os.Getenv, LogDirectoryConfigPath, discoverLogDirectory)
+}
+
+// discoverLogDirectory is logfile.DefaultLogDirectory without the
+// ErrNoLogDirectory prefix, which ResolveLogDirectory adds itself.
+func discoverLogDirectory() (string, error) {
+	dir, err := logfile.DefaultLogDirectory()
+	if err != nil && errors.Is(err, logfile.ErrNoLogDirectory) {
+		reason := strings.TrimPrefix(err.Error(), logfile.ErrNoLogDirectory.Error())
+		return "", errors.New(strings.TrimPrefix(reason, ": "))
+	}
+	return dir, err
+}
+
+// unsetError rejects a rule that names no directory. Resolution moves
+// on to the next rule after it, and stops at any other rejection.
+type unsetError string
+
+func (e unsetError) Error() string {
+	return string(e)
+}
+
+func resolveLogDirectory(getenv func(string) string, configPath func() (string, error), discover func() (string, error)) (LogDirectoryResolution, error) {
+	var res LogDirectoryResolution
+	// try reports whether c resolved the directory, and the error that
+	// ends the resolution if it named an unusable one.
+	try := func(c LogDirectoryCandidate, err error) (bool, error) {
+		if err == nil {
+			c.Dir, err = checkLogDirectory(c.Dir)
+		}
+		if err != nil {
+			c.Rejected = err.Error()
+			res.Candidates = append(res.Candidates, c)
+			if _, unset := err.(unsetError); unset {
+				return false, nil
+			}
+			return false, fmt.Errorf("%w: %s: %s", ErrNoLogDirectory, c.Rule, c.Rejected)
+		}
+		res.Candidates = append(res.Candidates, c)
+		res.Dir, res.Rule = c.Dir, c.Rule
+		return true, nil
+	}
+
+	env := LogDirectoryCandidate{Rule: LogDirectoryFromEnv, Origin: LogDirectoryEnv, Dir: getenv(LogDirectoryEnv)}
+	var err error
+	if env.Dir == "" {
+		err = unsetError("not set")
+	}
+	if ok, err := try(env, err); ok || err != nil {
+		return res, err
+	}
+
+	config := LogDirectoryCandidate{Rule: LogDirectoryFromConfig}
+	config.Origin, err = configPath()
+	if err != nil {
+		err = unsetError(err.Error())
+	} else {
+		config.Dir, err = readLogDirectoryConfig(config.Origin)
+	}
+	if ok, err := try(config, err); ok || err != nil {
+		return res, err
+	}
+
+	// Discovery is the last rule, so its rejection is reported with the
+	// others below.
+	discovered := LogDirectoryCandidate{Rule: LogDirectoryFromDiscovery}
+	discovered.Dir, err = discover()
+	if ok, _ := try(discovered, err); ok {
+		return res, nil
+	}
+
+	reasons := make([]string, len(res.Candidates))
+	for i, c := range res.Candidates {
+		reasons[i] = fmt.Sprintf("%s: %s", c.Rule, c.Rejected)
+	}
+	return res, fmt.Errorf("%w: %s", ErrNoLogDirectory, strings.Join(reasons, "; "))
+}
+
+// readLogDirectoryConfig returns the log directory named by the config
+// file at path. The error is an unsetError if the file does not name
+// one.
+func readLogDirectoryConfig(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", unsetError("no config file")
+	}
+	if err != nil {
+		return "", err
+	}
+	var cfg logDirectoryConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return "", fmt.Errorf("parse config file: %w", err)
+	}
+	if cfg.LogDirectory == "" {
+		return "", unsetError("config file has no log_directory")
+	}
+	if !filepath.IsAbs(cfg.LogDirectory) {
+		return "", fmt.Errorf("log_directory %q is not an absolute path", cfg.LogDirectory)
+	}
+	return cfg.LogDirectory, nil
+}
+
+// checkLogDirectory returns dir made absolute, with symlinks resolved as
+// platform discovery does, if it is an existing directory.
+func checkLogDirectory(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", abs)
+	}
+	return filepath.EvalSymlinks(abs)
+}
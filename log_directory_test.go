@@ -0,0 +1,170 @@
+package vrclog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeLogDirectorySources returns resolveLogDirectory's sources for an
+// environment, a config file path and a discovery result.
+func fakeLogDirectorySources(env map[string]string, configPath, discovered string) (func(string) string, func() (string, error), func() (string, error)) {
+	getenv := func(key string) string { return env[key] }
+	config := func() (string, error) { return configPath, nil }
+	discover := func() (string, error) {
+		if discovered == "" {
+			return "", errors.New("VRChat log directory not found")
+		}
+		return discovered, nil
+	}
+	return getenv, config, discover
+}
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func mustEvalSymlinks(t *testing.T, path string) string {
+	t.Helper()
+	p, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestResolveLogDirectory_Precedence(t *testing.T) {
+	envDir, configDir, discovered := t.TempDir(), t.TempDir(), t.TempDir()
+	config := writeConfig(t, `{"log_directory": "`+filepath.ToSlash(configDir)+`"}`)
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+		rule LogDirectoryRule
+		n    int
+	}{
+		{"env", map[string]string{LogDirectoryEnv: envDir}, envDir, LogDirectoryFromEnv, 1},
+		{"config", nil, configDir, LogDirectoryFromConfig, 2},
+		{"env empty falls through", map[string]string{LogDirectoryEnv: ""}, configDir, LogDirectoryFromConfig, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := resolveLogDirectory(fakeLogDirectorySources(tt.env, config, discovered))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Dir != mustEvalSymlinks(t, tt.want) || res.Rule != tt.rule {
+				t.Errorf("resolved %s by %s, want %s by %s", res.Dir, res.Rule, tt.want, tt.rule)
+			}
+			if len(res.Candidates) != tt.n {
+				t.Fatalf("candidates = %+v, want %d", res.Candidates, tt.n)
+			}
+			for i, c := range res.Candidates {
+				last := i == len(res.Candidates)-1
+				if (c.Rejected == "") != last {
+					t.Errorf("candidate %d = %+v, want only the last one accepted", i, c)
+				}
+			}
+		})
+	}
+}
+
+func TestResolveLogDirectory_Discovery(t *testing.T) {
+	discovered := t.TempDir()
+	res, err := resolveLogDirectory(fakeLogDirectorySources(nil, filepath.Join(t.TempDir(), "config.json"), discovered))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Rule != LogDirectoryFromDiscovery || res.Dir != mustEvalSymlinks(t, discovered) {
+		t.Errorf("resolution = %+v, want discovery", res)
+	}
+	want := []LogDirectoryCandidate{
+		{Rule: LogDirectoryFromEnv, Origin: LogDirectoryEnv, Rejected: "not set"},
+		{Rule: LogDirectoryFromConfig, Origin: res.Candidates[1].Origin, Rejected: "no config file"},
+		{Rule: LogDirectoryFromDiscovery, Dir: res.Dir},
+	}
+	for i, c := range res.Candidates {
+		if c != want[i] {
+			t.Errorf("candidate %d = %+v, want %+v", i, c, want[i])
+		}
+	}
+}
+
+func TestResolveLogDirectory_InvalidSettingsFail(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	discovered := t.TempDir()
+	tests := []struct {
+		name   string
+		env    string
+		config string
+		rule   LogDirectoryRule
+	}{
+		{"env missing", filepath.Join(discovered, "gone"), "", LogDirectoryFromEnv},
+		{"env not a directory", file, "", LogDirectoryFromEnv},
+		{"config invalid json", "", `{"log_directory":`, LogDirectoryFromConfig},
+		{"config relative", "", `{"log_directory": "logs"}`, LogDirectoryFromConfig},
+		{"config not a directory", "", `{"log_directory": "` + filepath.ToSlash(file) + `"}`, LogDirectoryFromConfig},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := filepath.Join(t.TempDir(), "config.json")
+			if tt.config != "" {
+				config = writeConfig(t, tt.config)
+			}
+			env := map[string]string{LogDirectoryEnv: tt.env}
+			res, err := resolveLogDirectory(fakeLogDirectorySources(env, config, discovered))
+			if !errors.Is(err, ErrNoLogDirectory) {
+				t.Fatalf("error = %v, want ErrNoLogDirectory", err)
+			}
+			if res.Dir != "" {
+				t.Fatalf("resolved %s, want no fallback to discovery", res.Dir)
+			}
+			last := res.Candidates[len(res.Candidates)-1]
+			if last.Rule != tt.rule || last.Rejected == "" {
+				t.Errorf("candidates = %+v, want a %s rejection last", res.Candidates, tt.rule)
+			}
+		})
+	}
+}
+
+func TestResolveLogDirectory_ConfigWithoutKeyFallsThrough(t *testing.T) {
+	discovered := t.TempDir()
+	res, err := resolveLogDirectory(fakeLogDirectorySources(nil, writeConfig(t, `{}`), discovered))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Rule != LogDirectoryFromDiscovery || len(res.Candidates) != 3 || res.Candidates[1].Rejected == "" {
+		t.Errorf("resolution = %+v, want discovery after a rejected config", res)
+	}
+}
+
+func TestDefaultLogDirectory_Env(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(LogDirectoryEnv, dir)
+	got, err := DefaultLogDirectory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != mustEvalSymlinks(t, dir) {
+		t.Errorf("DefaultLogDirectory() = %s, want %s", got, dir)
+	}
+
+	writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt", logLine("2024.01.01 00:00:01", "a"))
+	snap, err := CaptureLogSnapshot("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snap.Files()) != 1 {
+		t.Errorf("snapshot has %d files, want the one in %s", len(snap.Files()), LogDirectoryEnv)
+	}
+}
@@ -4,25 +4,40 @@ import (
 	"bufio"
 	"crypto/sha256"
 	"encoding/hex"
+	"hash"
 	"io"
 	"strconv"
+	"strings"
 )
 
-const maxLineSize = 1 << 20
-
 const lineReaderBufSize = 64 * 1024
 
 type lineReader struct {
-	br     *bufio.Reader
-	offset int64
-	line   uint64
+	br       *bufio.Reader
+	offset   int64
+	line     uint64
+	oversize OversizeConfig
+	// enc is the encoding lines are framed and decoded in, and bom the
+	// byte order mark found at offset 0, if any.
+	enc Encoding
+	bom string
+	// unhashed skips hashing lines; next then returns a zero rawHash.
+	unhashed bool
+	// pending is the length of the unterminated fragment returned by the
+	// last next() call, or 0 if that line was terminated.
+	pending int64
 }
 
-func newLineReader(r io.Reader, startOffset int64, startLine uint64) *lineReader {
+// newLineReader returns a lineReader for r, whose first byte is at
+// startOffset. A byte order mark at offset 0 overrides the encoding in
+// opts.
+func newLineReader(r io.Reader, startOffset int64, startLine uint64, opts recordOptions) *lineReader {
 	return &lineReader{
-		br:     bufio.NewReaderSize(r, lineReaderBufSize),
-		offset: startOffset,
-		line:   startLine,
+		br:       bufio.NewReaderSize(r, lineReaderBufSize),
+		offset:   startOffset,
+		line:     startLine,
+		oversize: opts.oversize,
+		enc:      opts.textEncoding(),
 	}
 }
 
@@ -31,62 +46,117 @@ func newLineReader(r io.Reader, startOffset int64, startLine uint64) *lineReader
 // reached with unterminated trailing data), lr.offset and lr.line are
 // NOT advanced — the next call to next() (against a fresh reader seeked
 // to the same position) will re-read the same fragment from its start.
+//
+// raw is always UTF-8. A line longer than the oversize limit is hashed
+// in full but raw keeps only what the oversize policy retains, and issue
+// describes the cut. Otherwise bytes invalid in the encoding are
+// replaced and reported by issue.
 func (lr *lineReader) next() (raw []byte, rawHash [32]byte, offset int64, nextOffset int64, line uint64, terminated bool, issue *RecordIssue, err error) {
 	lineStart := lr.offset
 	lineNum := lr.line
+	if lineStart == 0 {
+		if err = lr.sniff(); err != nil {
+			return
+		}
+	}
+	limit := lr.oversize.maxLineSize()
+	unit := lr.enc.unitSize()
+	headSize, tailSize := lr.oversize.retained(unit)
+	// tail keeps room for a CRLF terminator so that it can be stripped.
+	tailKeep := tailSize + 2*unit
 
-	h := sha256.New()
+	var h hash.Hash
+	if !lr.unhashed {
+		h = sha256.New()
+	}
 	var accumulated []byte
+	var tail []byte
 	var totalBytes int64
 	var oversized bool
 	var hasData bool
+	var lastByte byte
+
+	add := func(fragment []byte) {
+		if h != nil {
+			h.Write(fragment)
+		}
+		totalBytes += int64(len(fragment))
+		lastByte = fragment[len(fragment)-1]
+
+		switch {
+		case oversized:
+			tail = keepTail(append(tail, fragment...), tailKeep)
+		case totalBytes <= limit:
+			accumulated = append(accumulated, fragment...)
+		default:
+			// Crossed the limit: split what was read so far into the
+			// retained head and the start of the tail.
+			oversized = true
+			full := append(accumulated, fragment...)
+			tail = keepTail(append([]byte(nil), full[headSize:]...), tailKeep)
+			accumulated = full[:headSize:headSize]
+		}
+	}
 
 	for {
 		fragment, readErr := lr.br.ReadSlice('\n')
 
 		if len(fragment) > 0 {
 			hasData = true
-			h.Write(fragment)
-			totalBytes += int64(len(fragment))
-
-			if !oversized {
-				if totalBytes <= maxLineSize {
-					cp := make([]byte, len(fragment))
-					copy(cp, fragment)
-					accumulated = append(accumulated, cp...)
-				} else {
-					// Crossed the threshold mid-fragment: keep only up to maxLineSize
-					keep := int64(len(fragment)) - (totalBytes - maxLineSize)
-					if keep > 0 {
-						cp := make([]byte, keep)
-						copy(cp, fragment[:keep])
-						accumulated = append(accumulated, cp...)
+			before := lastByte
+			if len(fragment) > 1 {
+				before = fragment[len(fragment)-2]
+			}
+			add(fragment)
+
+			if readErr == nil {
+				// In UTF-16 a '\n' byte only ends the line if it is part
+				// of a newline code unit.
+				switch lr.enc {
+				case EncodingUTF16LE:
+					if totalBytes%2 == 0 {
+						continue
+					}
+					next, peekErr := lr.br.Peek(1)
+					if peekErr != nil && peekErr != io.EOF {
+						err = peekErr
+						return
+					}
+					if len(next) == 0 || next[0] != 0 {
+						continue
+					}
+					lr.br.Discard(1)
+					add([]byte{0})
+				case EncodingUTF16BE:
+					if totalBytes%2 != 0 || before != 0 {
+						continue
 					}
-					oversized = true
 				}
 			}
 		}
 
 		if readErr == nil {
-			// Found '\n' — line complete
+			// Found the newline — line complete
 			lr.offset = lineStart + totalBytes
-			lr.line = lineNum + 1
+			lr.line = nextLine(lineNum)
+			lr.pending = 0
 
-			// Strip terminator from accumulated for Raw field
-			raw = stripTerminator(accumulated)
-
-			copy(rawHash[:], h.Sum(nil))
+			if h != nil {
+				copy(rawHash[:], h.Sum(nil))
+			}
 			offset = lineStart
 			nextOffset = lr.offset
 			line = lineNum
 			terminated = true
 
 			if oversized {
-				issue = &RecordIssue{
-					Code:    "line_too_long",
-					Message: "line exceeds maximum size of " + strconv.FormatInt(maxLineSize, 10) + " bytes",
-				}
+				content := lr.enc.stripTerminator(tail)
+				raw, issue = lr.oversize.cut(accumulated, content, unit, lineStart, totalBytes-int64(len(tail)-len(content)))
+			} else {
+				// Strip terminator from accumulated for Raw field
+				raw = lr.enc.stripTerminator(accumulated)
 			}
+			raw, issue = lr.text(raw, lineStart, issue)
 			return
 		}
 
@@ -106,19 +176,20 @@ func (lr *lineReader) next() (raw []byte, rawHash [32]byte, offset int64, nextOf
 			// unterminated data (active Follow) rely on this to safely
 			// re-read the same fragment from a fresh reader later.
 			raw = accumulated
+			lr.pending = totalBytes
 
-			copy(rawHash[:], h.Sum(nil))
+			if h != nil {
+				copy(rawHash[:], h.Sum(nil))
+			}
 			offset = lineStart
 			nextOffset = lineStart + totalBytes
 			line = lineNum
 			terminated = false
 
 			if oversized {
-				issue = &RecordIssue{
-					Code:    "line_too_long",
-					Message: "line exceeds maximum size of " + strconv.FormatInt(maxLineSize, 10) + " bytes",
-				}
+				raw, issue = lr.oversize.cut(accumulated, tail, unit, lineStart, totalBytes)
 			}
+			raw, issue = lr.text(raw, lineStart, issue)
 			return
 		}
 
@@ -128,6 +199,63 @@ func (lr *lineReader) next() (raw []byte, rawHash [32]byte, offset int64, nextOf
 	}
 }
 
+// sniff looks for a byte order mark at the start of the source. Without
+// one, lr keeps the encoding it was created with.
+func (lr *lineReader) sniff() error {
+	head, err := lr.br.Peek(3)
+	if err != nil && err != io.EOF {
+		return err
+	}
+	if enc, bom, ok := detectEncoding(head); ok {
+		lr.enc, lr.bom = enc, bom
+	}
+	return nil
+}
+
+// text converts raw, the content of the line starting at lineStart, to
+// UTF-8 and drops the byte order mark from the first line. Unless the
+// line already has an issue (an oversized line is not contiguous in the
+// file), invalid bytes are reported with their offsets in the file.
+func (lr *lineReader) text(raw []byte, lineStart int64, issue *RecordIssue) ([]byte, *RecordIssue) {
+	base := lineStart
+	if lineStart == 0 && lr.bom != "" && strings.HasPrefix(string(raw), lr.bom) {
+		raw = raw[len(lr.bom):]
+		base += int64(len(lr.bom))
+	}
+	text, invalid, count := lr.enc.decode(raw)
+	if count == 0 || issue != nil {
+		return text, issue
+	}
+	for i := range invalid {
+		invalid[i].Start += base
+		invalid[i].End += base
+	}
+	return text, &RecordIssue{
+		Code:         RecordIssueInvalidEncoding,
+		Message:      strconv.FormatInt(count, 10) + " bytes are not valid " + lr.enc.String(),
+		Invalid:      invalid,
+		InvalidBytes: count,
+	}
+}
+
+// keepTail trims b to its last n bytes once it holds more than 2n, so
+// that tracking the tail of a long line costs amortized O(1) per byte.
+func keepTail(b []byte, n int) []byte {
+	if len(b) <= 2*n {
+		return b
+	}
+	return append(b[:0], b[len(b)-n:]...)
+}
+
+// nextLine returns the line number following line. An unknown line
+// number stays unknown.
+func nextLine(line uint64) uint64 {
+	if line == LineUnknown {
+		return LineUnknown
+	}
+	return line + 1
+}
+
 func stripTerminator(b []byte) []byte {
 	if len(b) == 0 {
 		return b
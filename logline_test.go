@@ -11,7 +11,7 @@ import (
 
 func TestLineReader_LF(t *testing.T) {
 	input := "line1\nline2\nline3\n"
-	lr := newLineReader(strings.NewReader(input), 0, 1)
+	lr := newLineReader(strings.NewReader(input), 0, 1, recordOptions{})
 
 	expected := []struct {
 		raw        string
@@ -54,7 +54,7 @@ func TestLineReader_LF(t *testing.T) {
 
 func TestLineReader_CRLF(t *testing.T) {
 	input := "line1\r\nline2\r\n"
-	lr := newLineReader(strings.NewReader(input), 0, 1)
+	lr := newLineReader(strings.NewReader(input), 0, 1, recordOptions{})
 
 	raw, _, offset, nextOffset, line, _, _, err := lr.next()
 	if err != nil {
@@ -81,7 +81,7 @@ func TestLineReader_CRLF(t *testing.T) {
 
 func TestLineReader_FinalLineNoTerminator(t *testing.T) {
 	input := "line1\nline2"
-	lr := newLineReader(strings.NewReader(input), 0, 1)
+	lr := newLineReader(strings.NewReader(input), 0, 1, recordOptions{})
 
 	raw, _, _, _, _, _, _, err := lr.next()
 	if err != nil {
@@ -110,7 +110,7 @@ func TestLineReader_FinalLineNoTerminator(t *testing.T) {
 
 func TestLineReader_BlankLine(t *testing.T) {
 	input := "line1\n\nline3\n"
-	lr := newLineReader(strings.NewReader(input), 0, 1)
+	lr := newLineReader(strings.NewReader(input), 0, 1, recordOptions{})
 
 	raw, _, _, _, _, _, _, err := lr.next()
 	if err != nil {
@@ -140,9 +140,24 @@ func TestLineReader_BlankLine(t *testing.T) {
 	}
 }
 
+func TestLineReader_Unhashed(t *testing.T) {
+	lr := newLineReader(strings.NewReader("line1\nline2"), 0, 1, recordOptions{})
+	lr.unhashed = true
+
+	for _, want := range []string{"line1", "line2"} {
+		raw, rawHash, _, _, _, _, _, err := lr.next()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", want, err)
+		}
+		if string(raw) != want || rawHash != [32]byte{} {
+			t.Errorf("raw = %q, hash = %x; want %q and a zero hash", raw, rawHash, want)
+		}
+	}
+}
+
 func TestLineReader_OffsetAccuracy(t *testing.T) {
 	input := "abc\ndefgh\ni\n"
-	lr := newLineReader(strings.NewReader(input), 0, 1)
+	lr := newLineReader(strings.NewReader(input), 0, 1, recordOptions{})
 
 	type check struct {
 		offset, nextOffset int64
@@ -167,7 +182,7 @@ func TestLineReader_OffsetAccuracy(t *testing.T) {
 
 func TestLineReader_LineNumbering(t *testing.T) {
 	input := "a\nb\nc\n"
-	lr := newLineReader(strings.NewReader(input), 0, 1)
+	lr := newLineReader(strings.NewReader(input), 0, 1, recordOptions{})
 
 	for i := uint64(1); i <= 3; i++ {
 		_, _, _, _, line, _, _, err := lr.next()
@@ -182,7 +197,7 @@ func TestLineReader_LineNumbering(t *testing.T) {
 
 func TestLineReader_StartingOffset(t *testing.T) {
 	input := "hello\n"
-	lr := newLineReader(strings.NewReader(input), 100, 5)
+	lr := newLineReader(strings.NewReader(input), 100, 5, recordOptions{})
 
 	_, _, offset, nextOffset, line, _, _, err := lr.next()
 	if err != nil {
@@ -237,7 +252,7 @@ func TestRecordID_DifferentOffset(t *testing.T) {
 
 func TestLineReader_RawHash(t *testing.T) {
 	input := "hello\n"
-	lr := newLineReader(strings.NewReader(input), 0, 1)
+	lr := newLineReader(strings.NewReader(input), 0, 1, recordOptions{})
 
 	_, rawHash, _, _, _, _, _, err := lr.next()
 	if err != nil {
@@ -254,7 +269,7 @@ func TestLineReader_RawHash(t *testing.T) {
 
 func TestLineReader_RawHashCRLF(t *testing.T) {
 	input := "hello\r\n"
-	lr := newLineReader(strings.NewReader(input), 0, 1)
+	lr := newLineReader(strings.NewReader(input), 0, 1, recordOptions{})
 
 	_, rawHash, _, _, _, _, _, err := lr.next()
 	if err != nil {
@@ -271,7 +286,7 @@ func TestLineReader_RawHashCRLF(t *testing.T) {
 
 func TestLineReader_RawHashNoTerminator(t *testing.T) {
 	input := "hello"
-	lr := newLineReader(strings.NewReader(input), 0, 1)
+	lr := newLineReader(strings.NewReader(input), 0, 1, recordOptions{})
 
 	_, rawHash, _, _, _, _, _, err := lr.next()
 	if err != nil {
@@ -287,11 +302,11 @@ func TestLineReader_RawHashNoTerminator(t *testing.T) {
 }
 
 func TestLineReader_OversizedLine(t *testing.T) {
-	bigLine := bytes.Repeat([]byte("X"), maxLineSize+100)
+	bigLine := bytes.Repeat([]byte("X"), DefaultMaxLineSize+100)
 	input := append(bigLine, '\n')
 	input = append(input, []byte("next line\n")...)
 
-	lr := newLineReader(bytes.NewReader(input), 0, 1)
+	lr := newLineReader(bytes.NewReader(input), 0, 1, recordOptions{})
 
 	raw, rawHash, offset, nextOffset, line, _, issue, err := lr.next()
 	if err != nil {
@@ -306,15 +321,15 @@ func TestLineReader_OversizedLine(t *testing.T) {
 	if offset != 0 {
 		t.Errorf("offset = %d, want 0", offset)
 	}
-	expectedNextOffset := int64(maxLineSize + 100 + 1)
+	expectedNextOffset := int64(DefaultMaxLineSize + 100 + 1)
 	if nextOffset != expectedNextOffset {
 		t.Errorf("nextOffset = %d, want %d", nextOffset, expectedNextOffset)
 	}
 	if line != 1 {
 		t.Errorf("line = %d, want 1", line)
 	}
-	if int64(len(raw)) > maxLineSize {
-		t.Errorf("raw length = %d, should be <= %d", len(raw), maxLineSize)
+	if int64(len(raw)) > DefaultMaxLineSize {
+		t.Errorf("raw length = %d, should be <= %d", len(raw), DefaultMaxLineSize)
 	}
 
 	// Verify hash covers ALL bytes including the discarded portion
@@ -348,9 +363,9 @@ func TestLineReader_OversizedLine(t *testing.T) {
 
 func TestLineReader_InvalidUTF8(t *testing.T) {
 	invalidBytes := []byte{0x80, 0x81, 0x82, '\n'}
-	lr := newLineReader(bytes.NewReader(invalidBytes), 0, 1)
+	lr := newLineReader(bytes.NewReader(invalidBytes), 0, 1, recordOptions{})
 
-	raw, rawHash, _, _, _, _, _, err := lr.next()
+	raw, rawHash, _, _, _, _, issue, err := lr.next()
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -361,14 +376,20 @@ func TestLineReader_InvalidUTF8(t *testing.T) {
 		t.Error("rawHash should be computed on original bytes, not sanitized")
 	}
 
-	// Raw is the original bytes minus terminator (NOT yet sanitized - sanitization is done in read.go)
-	if !bytes.Equal(raw, []byte{0x80, 0x81, 0x82}) {
-		t.Errorf("raw = %v, want original bytes minus terminator", raw)
+	// The run of invalid bytes becomes a single replacement character
+	if string(raw) != "\uFFFD" {
+		t.Errorf("raw = %q, want one replacement character", raw)
+	}
+	if issue == nil || issue.Code != RecordIssueInvalidEncoding {
+		t.Fatalf("issue = %+v, want invalid_encoding", issue)
+	}
+	if len(issue.Invalid) != 1 || issue.Invalid[0] != (ByteRange{Start: 0, End: 3}) || issue.InvalidBytes != 3 {
+		t.Errorf("invalid = %v (%d bytes), want [0,3) (3 bytes)", issue.Invalid, issue.InvalidBytes)
 	}
 }
 
 func TestLineReader_EmptyInput(t *testing.T) {
-	lr := newLineReader(strings.NewReader(""), 0, 1)
+	lr := newLineReader(strings.NewReader(""), 0, 1, recordOptions{})
 
 	_, _, _, _, _, _, _, err := lr.next()
 	if err != io.EOF {
@@ -0,0 +1,92 @@
+package vrclog
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// DefaultMaxLineSize is the line size limit used when
+// OversizeConfig.MaxLineSize is 0.
+const DefaultMaxLineSize = 1 << 20
+
+// OversizePolicy selects which bytes of an oversized line a Record keeps
+// in Raw and Message.
+type OversizePolicy uint8
+
+const (
+	// OversizeHead keeps the first MaxLineSize bytes.
+	OversizeHead OversizePolicy = iota
+
+	// OversizeHeadTail keeps the first and the last MaxLineSize/2 bytes,
+	// joined without a separator. RecordIssue.Omitted tells where the
+	// gap is. The tail is where a large JSON dump keeps its closing data.
+	OversizeHeadTail
+
+	// OversizeHashOnly keeps nothing: Raw and Message are empty and only
+	// the RecordID, derived from the hash of the whole line, remains.
+	OversizeHashOnly
+)
+
+// OversizeConfig selects how lines longer than a limit are handled.
+// Every policy hashes the whole line, so RecordIDs do not depend on it.
+// An oversized record carries a RecordIssueLineTooLong issue.
+type OversizeConfig struct {
+	// MaxLineSize is the largest line, including its newline, that is
+	// kept whole. 0 means DefaultMaxLineSize.
+	MaxLineSize int64
+
+	Policy OversizePolicy
+}
+
+func (c OversizeConfig) validate() error {
+	if c.MaxLineSize < 0 {
+		return errors.New("max line size must not be negative")
+	}
+	if c.Policy > OversizeHashOnly {
+		return fmt.Errorf("unknown oversize policy %d", c.Policy)
+	}
+	return nil
+}
+
+func (c OversizeConfig) maxLineSize() int64 {
+	if c.MaxLineSize == 0 {
+		return DefaultMaxLineSize
+	}
+	return c.MaxLineSize
+}
+
+// retained returns how many leading and trailing bytes of an oversized
+// line the policy keeps, in whole code units of unit bytes.
+func (c OversizeConfig) retained(unit int) (head, tail int) {
+	limit := int(c.maxLineSize())
+	switch c.Policy {
+	case OversizeHeadTail:
+		head, tail = limit/2, limit-limit/2
+	case OversizeHashOnly:
+		return 0, 0
+	default:
+		head = limit
+	}
+	return head - head%unit, tail - tail%unit
+}
+
+// cut assembles the raw bytes kept of an oversized line starting at
+// lineStart, from its retained head and content, the last bytes read
+// without the terminator. contentLen is the length of the line without
+// its terminator, and unit the code unit size of its encoding.
+func (c OversizeConfig) cut(head, content []byte, unit int, lineStart, contentLen int64) ([]byte, *RecordIssue) {
+	head = head[:min(int64(len(head)), contentLen)]
+	_, tailSize := c.retained(unit)
+	n := min(int64(tailSize), contentLen-int64(len(head)), int64(len(content)))
+	content = content[int64(len(content))-n:]
+
+	raw := make([]byte, 0, len(head)+len(content))
+	raw = append(raw, head...)
+	raw = append(raw, content...)
+	return raw, &RecordIssue{
+		Code:    RecordIssueLineTooLong,
+		Message: "line exceeds maximum size of " + strconv.FormatInt(c.maxLineSize(), 10) + " bytes",
+		Omitted: &ByteRange{Start: lineStart + int64(len(head)), End: lineStart + contentLen - n},
+	}
+}
@@ -0,0 +1,101 @@
+package vrclog
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func readAllRecords(t *testing.T, content string, oversize OversizeConfig) []Record {
+	t.Helper()
+	return collectSeq(t, ReadRecords(context.Background(), strings.NewReader(content), ReaderConfig{SourceID: "src", Oversize: oversize}))
+}
+
+func TestOversizePolicies(t *testing.T) {
+	long := "2024.01.01 00:00:01 Log        -  {\"head\":" + strings.Repeat("x", 200) + ",\"tail\":1}"
+	content := long + "\r\n" + logLine("2024.01.01 00:00:02", "short")
+
+	cases := []struct {
+		name    string
+		cfg     OversizeConfig
+		raw     string
+		omitted ByteRange
+	}{
+		{"head", OversizeConfig{MaxLineSize: 64}, long[:64], ByteRange{Start: 64, End: int64(len(long))}},
+		{"head+tail", OversizeConfig{MaxLineSize: 64, Policy: OversizeHeadTail}, long[:32] + long[len(long)-32:], ByteRange{Start: 32, End: int64(len(long) - 32)}},
+		{"hash only", OversizeConfig{MaxLineSize: 64, Policy: OversizeHashOnly}, "", ByteRange{Start: 0, End: int64(len(long))}},
+	}
+	var id RecordID
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			records := readAllRecords(t, content, tc.cfg)
+			if len(records) != 2 {
+				t.Fatalf("got %d records, want 2", len(records))
+			}
+			rec := records[0]
+			if rec.Raw != tc.raw {
+				t.Errorf("Raw = %q, want %q", rec.Raw, tc.raw)
+			}
+			if rec.Issue == nil || rec.Issue.Code != RecordIssueLineTooLong || rec.Issue.Omitted == nil || *rec.Issue.Omitted != tc.omitted {
+				t.Errorf("Issue = %+v, want line_too_long omitting %+v", rec.Issue, tc.omitted)
+			}
+			if rec.NextOffset != int64(len(long)+2) || records[1].Issue != nil || records[1].Message != "short" {
+				t.Errorf("framing after the oversized line is off: %+v", records[1])
+			}
+			if id == "" {
+				id = rec.ID
+			} else if rec.ID != id {
+				t.Error("RecordID must not depend on the oversize policy")
+			}
+		})
+	}
+}
+
+func TestOversizeHeadTailAcrossBuffers(t *testing.T) {
+	long := "2024.01.01 00:00:01 Log        -  " + strings.Repeat("a", 3*lineReaderBufSize) + "THE END"
+	records := readAllRecords(t, long+"\n", OversizeConfig{MaxLineSize: 100, Policy: OversizeHeadTail})
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	if want := long[:50] + long[len(long)-50:]; records[0].Raw != want {
+		t.Errorf("Raw = %q, want %q", records[0].Raw, want)
+	}
+	if !strings.HasSuffix(records[0].Message, "THE END") {
+		t.Errorf("Message = %q, want the tail kept", records[0].Message)
+	}
+}
+
+func TestOversizeUnterminatedFinalLine(t *testing.T) {
+	long := strings.Repeat("z", 100)
+	records := readAllRecords(t, long, OversizeConfig{MaxLineSize: 10, Policy: OversizeHeadTail})
+	if len(records) != 1 || records[0].Raw != long[:5]+long[95:] {
+		t.Fatalf("records = %+v", records)
+	}
+	if got := *records[0].Issue.Omitted; got != (ByteRange{Start: 5, End: 95}) {
+		t.Errorf("Omitted = %+v, want [5, 95)", got)
+	}
+}
+
+func TestOversizeTerminatorAtLimit(t *testing.T) {
+	// 9 content bytes and CRLF exceed a limit of 10, but nothing of the
+	// content is lost.
+	records := readAllRecords(t, "123456789\r\n", OversizeConfig{MaxLineSize: 10, Policy: OversizeHeadTail})
+	if len(records) != 1 || records[0].Raw != "123456789" {
+		t.Fatalf("records = %+v", records)
+	}
+	if got := records[0].Issue.Omitted.Len(); got != 0 {
+		t.Errorf("Omitted length = %d, want 0", got)
+	}
+}
+
+func TestOversizeConfigValidation(t *testing.T) {
+	for _, cfg := range []OversizeConfig{{MaxLineSize: -1}, {Policy: OversizeHashOnly + 1}} {
+		var gotErr error
+		for _, err := range ReadFile(context.Background(), ReadFileConfig{Path: "unused", Oversize: cfg}) {
+			gotErr = err
+		}
+		if gotErr == nil || !strings.Contains(gotErr.Error(), "oversize") && !strings.Contains(gotErr.Error(), "max line size") {
+			t.Errorf("config %+v: error = %v, want a validation error", cfg, gotErr)
+		}
+	}
+}
@@ -5,18 +5,78 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"iter"
-	"path/filepath"
+	"time"
 
 	"github.com/vrclog/vrclog-go/internal/logfile"
 )
 
 type ReadFileConfig struct {
-	Path   string
+	Path string
+	// FS, when set, is the file system Path names a file in, using the
+	// slash-separated names of io/fs. Nil means the host file system.
+	// Files in FS that do not implement io.ReaderAt and io.Seeker are
+	// read sequentially, as archives are: Since does not jump, reads are
+	// never parallel, and cursors carry no head fingerprint.
+	FS fs.FS
+	// Entry names the log entry to read when Path is a zip archive. It
+	// may be empty if the archive holds exactly one output_log file.
+	Entry  string
 	Offset int64
 	Line   uint64
+
+	// Parallelism greater than 1 frames and hashes the file on that many
+	// goroutines. Records are still yielded in offset order and are
+	// identical to a sequential read; the file is read up to the size it
+	// had when opened. Archived and UTF-16 logs are always read
+	// sequentially.
+	Parallelism int
+
+	// Since and Until restrict the read to records whose header time
+	// lies in [Since, Until]. Reading starts at the first headed record
+	// at or after Since and stops at the first headed record after
+	// Until; header-less lines in between are kept. For plain files
+	// ReadFile binary-searches header timestamps to jump close to Since
+	// instead of reading from the start (UTF-8 files only). Zero values
+	// leave a side open.
+	Since time.Time
+	Until time.Time
+
+	// CountLines makes a Since jump count the newlines it skipped so
+	// that Line stays exact. Without it, records read after a jump have
+	// Line set to LineUnknown.
+	CountLines bool
+
+	// Location is the time zone header wall times are decoded in. Nil
+	// means time.Local. Wall times that are ambiguous or skipped around a
+	// DST transition in Location get a RecordIssueAmbiguousTime or
+	// RecordIssueNonexistentTime issue.
+	Location *time.Location
+
+	// Header decodes line headers, for logs whose headers differ from
+	// VRChat's release builds. Nil means DefaultHeaderDecoder.
+	Header HeaderDecoder
+
+	// Oversize selects the limit for a single line and what an oversized
+	// record keeps of it. The zero value keeps the first
+	// DefaultMaxLineSize bytes.
+	Oversize OversizeConfig
+
+	// Sessions, when set, collects the SessionInfo of the file.
+	Sessions *SessionExtractor
 }
 
+// ReadFile reads a single log file to its end. Files ending in .gz are
+// read through gzip and files ending in .zip through the selected
+// archive entry. Offsets always count uncompressed bytes, and the
+// SourceID of an archived log is that of the original .txt path (the
+// .gz suffix removed, or the entry's base name next to the archive), so
+// an archived copy yields the same RecordIDs as the original file did.
+//
+// A file starting with a UTF-16LE or UTF-16BE byte order mark is
+// transcoded to UTF-8; one starting with a UTF-8 mark has it removed
+// from the first record. Offsets still count the bytes of the file.
 func ReadFile(ctx context.Context, cfg ReadFileConfig) iter.Seq2[Record, error] {
 	return func(yield func(Record, error) bool) {
 		if cfg.Path == "" {
@@ -31,69 +91,215 @@ func ReadFile(ctx context.Context, cfg ReadFileConfig) iter.Seq2[Record, error]
 			yield(Record{}, errors.New("line is required when offset > 0"))
 			return
 		}
+		if cfg.Parallelism < 0 {
+			yield(Record{}, errors.New("parallelism must not be negative"))
+			return
+		}
+		if !cfg.Since.IsZero() && !cfg.Until.IsZero() && cfg.Until.Before(cfg.Since) {
+			yield(Record{}, errors.New("until must not be before since"))
+			return
+		}
+		if err := cfg.Oversize.validate(); err != nil {
+			yield(Record{}, err)
+			return
+		}
 
-		path, err := filepath.Abs(cfg.Path)
+		root := logfile.FSRoot(cfg.FS)
+		path, err := root.Clean(cfg.Path)
 		if err != nil {
 			yield(Record{}, err)
 			return
 		}
-		path = filepath.Clean(path)
 
 		startLine := cfg.Line
 		if startLine == 0 && cfg.Offset == 0 {
 			startLine = 1
 		}
 
-		f, info, err := logfile.OpenRegular(path)
+		src, err := openLogSource(root, path, cfg.Entry, cfg.Offset)
 		if err != nil {
 			yield(Record{}, err)
 			return
 		}
-		defer f.Close()
+		defer src.Close()
 
-		if cfg.Offset > info.Size() {
-			yield(Record{}, fmt.Errorf("%w: offset %d exceeds file size %d", ErrInvalidOffset, cfg.Offset, info.Size()))
+		opts := recordOptions{loc: cfg.Location, headers: cfg.Header, oversize: cfg.Oversize, sessions: cfg.Sessions}
+		if src.at != nil {
+			if opts.head, err = readSourceHead(src.at); err != nil {
+				yield(Record{}, err)
+				return
+			}
+		}
+		// Since jumps and parallel reads find lines by scanning for '\n'
+		// bytes, which only frames UTF-8.
+		scannable := src.at != nil && opts.textEncoding() == EncodingUTF8
+		start := cfg.Offset
+		if !cfg.Since.IsZero() && scannable {
+			jump, err := seekSince(src.at, start, src.size, cfg.Since, opts)
+			if err != nil {
+				yield(Record{}, err)
+				return
+			}
+			if jump > start {
+				if cfg.CountLines {
+					skipped, err := countNewlinesIn(src.at, start, jump)
+					if err != nil {
+						yield(Record{}, err)
+						return
+					}
+					startLine += skipped
+				} else {
+					startLine = LineUnknown
+				}
+				if err := src.seek(jump); err != nil {
+					yield(Record{}, err)
+					return
+				}
+				start = jump
+			}
+		}
+		// Reads past the head, and parallel ones, which leave line
+		// numbers unknown while framing, see no head lines.
+		if start > 0 || cfg.Parallelism > 1 {
+			if err := cfg.Sessions.scanHead(ctx, src.id, path, opts, headOf(root, path, cfg.Entry, src)); err != nil {
+				yield(Record{}, err)
+				return
+			}
+		}
+		if !cfg.Since.IsZero() || !cfg.Until.IsZero() {
+			yield = timeRangeYield(cfg.Since, cfg.Until, yield)
+		}
+
+		if cfg.Parallelism > 1 && scannable {
+			readParallel(ctx, src.at, start, src.size, startLine, cfg.Parallelism, src.id, path, opts, yield)
 			return
 		}
 
-		srcIDStr, err := logfile.SourceID(path)
-		if err != nil {
-			yield(Record{}, err)
+		readSequential(ctx, src, start, startLine, src.id, path, opts, yield)
+	}
+}
+
+// readSequential frames r, whose first byte is at offset start, to its
+// end. It has finite semantics: an unterminated final line is still
+// emitted, matching a settled read of the whole file.
+func readSequential(ctx context.Context, r io.Reader, start int64, startLine uint64, srcID SourceID, path string, opts recordOptions, yield func(Record, error) bool) {
+	lr := newLineReader(r, start, startLine, opts)
+
+	for {
+		if ctx.Err() != nil {
 			return
 		}
-		srcID := SourceID(srcIDStr)
 
-		if cfg.Offset > 0 {
-			if _, err := f.Seek(cfg.Offset, io.SeekStart); err != nil {
-				yield(Record{}, err)
-				return
-			}
+		rawBytes, rawHash, offset, nextOffset, lineNum, _, issue, readErr := lr.next()
+		if readErr == io.EOF {
+			return
+		}
+		if readErr != nil {
+			yield(Record{}, readErr)
+			return
 		}
 
-		lr := newLineReader(f, cfg.Offset, startLine)
+		rec := buildRecord(rawBytes, rawHash, offset, nextOffset, lineNum, issue, srcID, path, opts)
 
-		for {
-			if ctx.Err() != nil {
-				return
-			}
+		if !yield(rec, nil) {
+			return
+		}
+	}
+}
 
-			// ReadFile has finite semantics: an unterminated final line
-			// (terminated == false) is still emitted, matching a settled
-			// read of the whole file.
-			rawBytes, rawHash, offset, nextOffset, lineNum, _, issue, readErr := lr.next()
-			if readErr == io.EOF {
-				return
-			}
-			if readErr != nil {
-				yield(Record{}, readErr)
-				return
-			}
+// logSource is an open log positioned at a start offset.
+type logSource struct {
+	io.Reader
+	id SourceID
+	// size is the total uncompressed size, or -1 if unknown.
+	size int64
+	// at allows random access for plain files; nil for archives and for
+	// files of an fs.FS that only support sequential reads.
+	at     io.ReaderAt
+	closer io.Closer
+}
 
-			rec := buildRecord(rawBytes, rawHash, offset, nextOffset, lineNum, issue, srcID, path)
+func (s *logSource) Close() error {
+	return s.closer.Close()
+}
 
-			if !yield(rec, nil) {
-				return
+// seek repositions a plain file source.
+func (s *logSource) seek(off int64) error {
+	seeker, ok := s.Reader.(io.Seeker)
+	if !ok {
+		return errors.New("log source is not seekable")
+	}
+	_, err := seeker.Seek(off, io.SeekStart)
+	return err
+}
+
+// openLogSource opens a plain or archived log in root and positions it
+// at off. Plain files that support random access are seeked; archives
+// are decompressed, and they and other plain files have the first off
+// bytes discarded.
+func openLogSource(root logfile.Root, path, entry string, off int64) (*logSource, error) {
+	if logfile.CompressionOf(path) == logfile.CompressionNone {
+		if entry != "" {
+			return nil, fmt.Errorf("%s: entry is only valid for zip archives", path)
+		}
+		f, info, err := root.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		if off > info.Size() {
+			f.Close()
+			return nil, fmt.Errorf("%w: offset %d exceeds file size %d", ErrInvalidOffset, off, info.Size())
+		}
+		srcIDStr, err := root.SourceID(path)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		rf, ok := f.(logfile.File)
+		if !ok {
+			if err := discardPrefix(f, off); err != nil {
+				f.Close()
+				return nil, err
+			}
+			return &logSource{Reader: f, id: SourceID(srcIDStr), size: info.Size(), closer: f}, nil
+		}
+		if off > 0 {
+			if _, err := rf.Seek(off, io.SeekStart); err != nil {
+				rf.Close()
+				return nil, err
 			}
 		}
+		return &logSource{Reader: rf, id: SourceID(srcIDStr), size: info.Size(), at: rf, closer: rf}, nil
+	}
+
+	a, err := root.OpenArchived(path, entry)
+	if err != nil {
+		return nil, err
+	}
+	if a.Size >= 0 && off > a.Size {
+		a.Close()
+		return nil, fmt.Errorf("%w: offset %d exceeds uncompressed size %d", ErrInvalidOffset, off, a.Size)
+	}
+	srcIDStr, err := root.SourceID(logfile.LogicalPath(path, a.Entry))
+	if err != nil {
+		a.Close()
+		return nil, err
+	}
+	if err := discardPrefix(a, off); err != nil {
+		a.Close()
+		return nil, err
+	}
+	return &logSource{Reader: a, id: SourceID(srcIDStr), size: a.Size, closer: a}, nil
+}
+
+// discardPrefix reads past the first off bytes of r.
+func discardPrefix(r io.Reader, off int64) error {
+	if off == 0 {
+		return nil
+	}
+	n, err := io.CopyN(io.Discard, r, off)
+	if err == io.EOF {
+		return fmt.Errorf("%w: offset %d exceeds uncompressed size %d", ErrInvalidOffset, off, n)
 	}
+	return err
 }
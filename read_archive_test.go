@@ -0,0 +1,170 @@
+package vrclog
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func gzipBytes(t *testing.T, content string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func zipBytes(t *testing.T, entries map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range entries {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func readAll(t *testing.T, cfg ReadFileConfig) []Record {
+	t.Helper()
+	var records []Record
+	for rec, err := range ReadFile(context.Background(), cfg) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+const archiveTestContent = "2024.01.01 00:00:01 Log        -  one\n" +
+	"2024.01.01 00:00:02 Log        -  two\n" +
+	"2024.01.01 00:00:03 Log        -  unterminated"
+
+func assertSameRecords(t *testing.T, got, want []Record) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d records, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].ID != want[i].ID || got[i].SourceID != want[i].SourceID ||
+			got[i].Offset != want[i].Offset || got[i].NextOffset != want[i].NextOffset ||
+			got[i].Line != want[i].Line || got[i].Raw != want[i].Raw {
+			t.Errorf("record %d differs:\n got  %+v\n want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestReadFile_GzipMatchesOriginal(t *testing.T) {
+	dir := t.TempDir()
+	txt := writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt", archiveTestContent)
+	original := readAll(t, ReadFileConfig{Path: txt})
+
+	if err := os.Remove(txt); err != nil {
+		t.Fatal(err)
+	}
+	gz := txt + ".gz"
+	if err := os.WriteFile(gz, gzipBytes(t, archiveTestContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	archived := readAll(t, ReadFileConfig{Path: gz})
+	assertSameRecords(t, archived, original)
+	if archived[0].Path != gz {
+		t.Errorf("Path = %q, want archive path %q", archived[0].Path, gz)
+	}
+
+	cursor := archived[0].Cursor()
+	resumed := readAll(t, ReadFileConfig{Path: gz, Offset: cursor.Offset, Line: cursor.Line})
+	assertSameRecords(t, resumed, original[1:])
+}
+
+func TestReadFile_ZipEntryMatchesOriginal(t *testing.T) {
+	dir := t.TempDir()
+	txt := writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt", archiveTestContent)
+	original := readAll(t, ReadFileConfig{Path: txt})
+	if err := os.Remove(txt); err != nil {
+		t.Fatal(err)
+	}
+
+	archive := filepath.Join(dir, "logs.zip")
+	if err := os.WriteFile(archive, zipBytes(t, map[string]string{
+		"VRChat/output_log_2024-01-01_00-00-00.txt": archiveTestContent,
+		"VRChat/output_log_2024-01-02_00-00-00.txt": "other\n",
+	}), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	archived := readAll(t, ReadFileConfig{Path: archive, Entry: "VRChat/output_log_2024-01-01_00-00-00.txt"})
+	assertSameRecords(t, archived, original)
+}
+
+func TestReadFile_ArchiveOffsetBeyondEnd(t *testing.T) {
+	gz := filepath.Join(t.TempDir(), "output_log_a.txt.gz")
+	if err := os.WriteFile(gz, gzipBytes(t, "short\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var gotErr error
+	for _, err := range ReadFile(context.Background(), ReadFileConfig{Path: gz, Offset: 100, Line: 2}) {
+		gotErr = err
+	}
+	if !errors.Is(gotErr, ErrInvalidOffset) {
+		t.Fatalf("expected ErrInvalidOffset, got %v", gotErr)
+	}
+}
+
+func TestReadFile_EntryRequiresZip(t *testing.T) {
+	path := writeLog(t, t.TempDir(), "line")
+
+	var gotErr error
+	for _, err := range ReadFile(context.Background(), ReadFileConfig{Path: path, Entry: "x"}) {
+		gotErr = err
+	}
+	if gotErr == nil {
+		t.Fatal("expected error for Entry on a plain file")
+	}
+}
+
+func TestReadDirectory_IncludeArchives(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "output_log_2024-01-01_00-00-00.txt.gz"),
+		gzipBytes(t, logLine("2024.01.01 00:00:01", "archived")+"archived tail"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeLogFile(t, dir, "output_log_2024-01-02_00-00-00.txt",
+		logLine("2024.01.02 00:00:01", "live")+"live partial")
+
+	plain := collectDirectory(t, ReadDirectoryConfig{Directory: dir})
+	if len(plain) != 1 || plain[0].Message != "live" {
+		t.Fatalf("without IncludeArchives got %+v", messages(plain))
+	}
+
+	all := collectDirectory(t, ReadDirectoryConfig{Directory: dir, IncludeArchives: true})
+	got := messages(all)
+	if len(got) != 3 || got[0] != "archived" || got[1] != "archived tail" || got[2] != "live" {
+		t.Fatalf("with IncludeArchives got %q", got)
+	}
+
+	cursor := all[0].Cursor()
+	resumed := collectDirectory(t, ReadDirectoryConfig{Directory: dir, IncludeArchives: true, Cursor: &cursor})
+	assertSameRecords(t, resumed, all[1:])
+}
@@ -0,0 +1,202 @@
+package vrclog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"iter"
+	"time"
+
+	"github.com/vrclog/vrclog-go/internal/logfile"
+)
+
+type ReadDirectoryConfig struct {
+	Directory string
+	// FS, when set, is the file system Directory names a directory in;
+	// see ReadFileConfig.FS. An empty Directory is then the root of FS.
+	FS     fs.FS
+	Cursor *Cursor
+
+	// IncludeArchives also reads gzip-compressed logs and output_log
+	// entries of zip archives in the directory; see ReadFile. Archived
+	// logs are always treated as settled.
+	IncludeArchives bool
+
+	// Location is the time zone header wall times are decoded in; see
+	// ReadFileConfig.Location.
+	Location *time.Location
+
+	// Header decodes line headers; see ReadFileConfig.Header.
+	Header HeaderDecoder
+
+	// Oversize selects how oversized lines are cut; see
+	// ReadFileConfig.Oversize.
+	Oversize OversizeConfig
+
+	// Sessions, when set, collects the SessionInfo of every file read.
+	Sessions *SessionExtractor
+}
+
+// ReadDirectory reads every VRChat output_log file in a directory,
+// oldest first, in the same order Follow visits them, and stops at the
+// end each file had when it was opened. An empty Directory uses
+// DefaultLogDirectory; a directory without log files yields nothing.
+//
+// Every file except the newest is settled, so its unterminated final
+// line (if any) is emitted. The newest file may still be written to, so
+// it is read with Follow's active-file semantics: an unterminated final
+// fragment is held back. The cursor of the last record is therefore
+// always a valid FollowConfig.Cursor or ReadDirectoryConfig.Cursor, and
+// resuming yields exactly the records a single uninterrupted read would.
+func ReadDirectory(ctx context.Context, cfg ReadDirectoryConfig) iter.Seq2[Record, error] {
+	return func(yield func(Record, error) bool) {
+		if err := cfg.Oversize.validate(); err != nil {
+			yield(Record{}, err)
+			return
+		}
+
+		root := logfile.FSRoot(cfg.FS)
+		dir := cfg.Directory
+		if dir == "" && root.IsHost() {
+			d, err := DefaultLogDirectory()
+			if err != nil {
+				yield(Record{}, err)
+				return
+			}
+			dir = d
+		}
+		dir, err := root.Clean(dir)
+		if err != nil {
+			yield(Record{}, err)
+			return
+		}
+
+		list := root.ListLogFilesStrict
+		if cfg.IncludeArchives {
+			list = root.ListLogFilesWithArchives
+		}
+		files, err := list(dir)
+		if err != nil {
+			if errors.Is(err, logfile.ErrNoLogFiles) {
+				return
+			}
+			yield(Record{}, fmt.Errorf("list log files: %w", err))
+			return
+		}
+
+		start := 0
+		var startOff int64
+		startLine := uint64(1)
+		if cfg.Cursor != nil {
+			idx, err := cursorFileIndex(root, files, cfg.Cursor)
+			if err != nil {
+				yield(Record{}, err)
+				return
+			}
+			if err := verifyDirectoryCursor(root, files[idx], cfg.Cursor); err != nil {
+				yield(Record{}, err)
+				return
+			}
+			start = idx
+			startOff = cfg.Cursor.Offset
+			startLine = cfg.Cursor.Line
+		}
+
+		opts := recordOptions{loc: cfg.Location, headers: cfg.Header, oversize: cfg.Oversize, sessions: cfg.Sessions}
+		for i := start; i < len(files); i++ {
+			if ctx.Err() != nil {
+				return
+			}
+			settled := i < len(files)-1 || files[i].Compression != logfile.CompressionNone
+			if !readDirectoryFile(ctx, root, files[i], startOff, startLine, settled, opts, yield) {
+				return
+			}
+			startOff, startLine = 0, 1
+		}
+	}
+}
+
+// cursorFileIndex locates the cursor's file in a directory listing. A
+// cursor whose file is no longer listed, or whose SourceID no longer
+// matches its path, reports ErrCursorSourceMissing.
+func cursorFileIndex(root logfile.Root, files []logfile.LogFileInfo, cursor *Cursor) (int, error) {
+	if cursor.Offset < 0 {
+		return 0, fmt.Errorf("%w: cursor offset %d is negative", ErrInvalidOffset, cursor.Offset)
+	}
+	if cursor.Offset > 0 && cursor.Line == 0 {
+		return 0, errors.New("cursor line is required when offset > 0")
+	}
+
+	path, err := root.Clean(cursor.Path)
+	if err != nil {
+		return 0, fmt.Errorf("cursor path: %w", err)
+	}
+
+	// Archived logs share their Path with other entries of the same zip
+	// archive, so a listed file must match on SourceID as well.
+	for i, f := range files {
+		if f.Path != path {
+			continue
+		}
+		srcID, err := root.SourceID(f.LogicalPath())
+		if err != nil {
+			return 0, fmt.Errorf("cursor source ID: %w", err)
+		}
+		if SourceID(srcID) == cursor.SourceID {
+			return i, nil
+		}
+	}
+	return 0, ErrCursorSourceMissing
+}
+
+// verifyDirectoryCursor checks a fingerprinted cursor against the
+// listed file it refers to.
+func verifyDirectoryCursor(root logfile.Root, info logfile.LogFileInfo, cursor *Cursor) error {
+	if cursor.Fingerprint.IsZero() {
+		return nil
+	}
+	src, err := openLogSource(root, info.Path, info.Entry, 0)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", info.Path, err)
+	}
+	defer src.Close()
+	return verifyCursorContent(src, cursor)
+}
+
+// readDirectoryFile reads a listed log from off up to the size it has
+// when opened. settled selects finite (flush the final fragment) or
+// active (hold it back) end-of-file semantics.
+func readDirectoryFile(ctx context.Context, root logfile.Root, info logfile.LogFileInfo, off int64, line uint64, settled bool, opts recordOptions, yield func(Record, error) bool) bool {
+	src, err := openLogSource(root, info.Path, info.Entry, off)
+	if err != nil {
+		yield(Record{}, fmt.Errorf("open %s: %w", info.Path, err))
+		return false
+	}
+	defer src.Close()
+
+	if src.at != nil {
+		if opts.head, err = readSourceHead(src.at); err != nil {
+			yield(Record{}, err)
+			return false
+		}
+	}
+	if off > 0 {
+		if err := opts.sessions.scanHead(ctx, src.id, info.Path, opts, headOf(root, info.Path, info.Entry, src)); err != nil {
+			yield(Record{}, err)
+			return false
+		}
+	}
+
+	var r io.Reader = src
+	if src.size >= 0 {
+		r = io.LimitReader(src, src.size-off)
+	}
+
+	lr := newLineReader(r, off, line, opts)
+	if settled {
+		return readFiniteRecords(ctx, lr, src.id, info.Path, opts, yield)
+	}
+	return readActiveRecords(ctx, lr, src.id, info.Path, opts, yield)
+}
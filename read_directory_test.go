@@ -0,0 +1,149 @@
+package vrclog
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func collectDirectory(t *testing.T, cfg ReadDirectoryConfig) []Record {
+	t.Helper()
+	var records []Record
+	for rec, err := range ReadDirectory(context.Background(), cfg) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+func TestReadDirectory_OldestFirstAcrossFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeLogFile(t, dir, "output_log_2024-01-02_00-00-00.txt",
+		logLine("2024.01.02 00:00:01", "second file"))
+	writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt",
+		logLine("2024.01.01 00:00:01", "first file a")+
+			logLine("2024.01.01 00:00:02", "first file b"))
+
+	records := collectDirectory(t, ReadDirectoryConfig{Directory: dir})
+
+	want := []string{"first file a", "first file b", "second file"}
+	if len(records) != len(want) {
+		t.Fatalf("got %d records, want %d", len(records), len(want))
+	}
+	for i, msg := range want {
+		if records[i].Message != msg {
+			t.Errorf("records[%d].Message = %q, want %q", i, records[i].Message, msg)
+		}
+	}
+	if records[2].Line != 1 || records[2].Offset != 0 {
+		t.Errorf("second file should restart at line 1 offset 0, got line %d offset %d", records[2].Line, records[2].Offset)
+	}
+	if records[0].SourceID == records[2].SourceID {
+		t.Error("records from different files share a SourceID")
+	}
+}
+
+func TestReadDirectory_FragmentSemantics(t *testing.T) {
+	dir := t.TempDir()
+	writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt",
+		logLine("2024.01.01 00:00:01", "settled")+"2024.01.01 00:00:02 Log        -  settled tail")
+	writeLogFile(t, dir, "output_log_2024-01-02_00-00-00.txt",
+		logLine("2024.01.02 00:00:01", "active")+"2024.01.02 00:00:02 Log        -  partial")
+
+	records := collectDirectory(t, ReadDirectoryConfig{Directory: dir})
+
+	want := []string{"settled", "settled tail", "active"}
+	if len(records) != len(want) {
+		t.Fatalf("got %d records, want %d", len(records), len(want))
+	}
+	for i, msg := range want {
+		if records[i].Message != msg {
+			t.Errorf("records[%d].Message = %q, want %q", i, records[i].Message, msg)
+		}
+	}
+}
+
+func TestReadDirectory_CursorResumeMatchesFullRead(t *testing.T) {
+	dir := t.TempDir()
+	writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt",
+		logLine("2024.01.01 00:00:01", "a")+logLine("2024.01.01 00:00:02", "b"))
+	writeLogFile(t, dir, "output_log_2024-01-02_00-00-00.txt",
+		logLine("2024.01.02 00:00:01", "c")+logLine("2024.01.02 00:00:02", "d"))
+
+	full := collectDirectory(t, ReadDirectoryConfig{Directory: dir})
+	if len(full) != 4 {
+		t.Fatalf("got %d records, want 4", len(full))
+	}
+
+	cursor := full[0].Cursor()
+	resumed := collectDirectory(t, ReadDirectoryConfig{Directory: dir, Cursor: &cursor})
+
+	if len(resumed) != 3 {
+		t.Fatalf("got %d resumed records, want 3", len(resumed))
+	}
+	for i, rec := range resumed {
+		if rec.ID != full[i+1].ID || rec.Line != full[i+1].Line {
+			t.Errorf("resumed[%d] = (%s, line %d), want (%s, line %d)", i, rec.ID, rec.Line, full[i+1].ID, full[i+1].Line)
+		}
+	}
+
+	last := full[3].Cursor()
+	if tail := collectDirectory(t, ReadDirectoryConfig{Directory: dir, Cursor: &last}); len(tail) != 0 {
+		t.Errorf("resume from final cursor yielded %d records, want 0", len(tail))
+	}
+}
+
+func TestReadDirectory_EmptyDirectory(t *testing.T) {
+	if records := collectDirectory(t, ReadDirectoryConfig{Directory: t.TempDir()}); len(records) != 0 {
+		t.Fatalf("got %d records, want 0", len(records))
+	}
+}
+
+func TestReadDirectory_CursorSourceMissing(t *testing.T) {
+	dir := t.TempDir()
+	writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt", logLine("2024.01.01 00:00:01", "a"))
+
+	cursor := Cursor{
+		SourceID: "nonexistent",
+		Path:     filepath.Join(dir, "output_log_2023-01-01_00-00-00.txt"),
+		Line:     1,
+	}
+
+	var gotErr error
+	for _, err := range ReadDirectory(context.Background(), ReadDirectoryConfig{Directory: dir, Cursor: &cursor}) {
+		gotErr = err
+		break
+	}
+	if !errors.Is(gotErr, ErrCursorSourceMissing) {
+		t.Fatalf("expected ErrCursorSourceMissing, got %v", gotErr)
+	}
+}
+
+func TestReadDirectory_FS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"output_log_2024-01-01_00-00-00.txt.gz": {Data: gzipBytes(t, logLine("2024.01.01 00:00:01", "archived"))},
+		"output_log_2024-01-02_00-00-00.txt": {Data: []byte(
+			logLine("2024.01.02 00:00:01", "a") + logLine("2024.01.02 00:00:02", "b"))},
+	}
+
+	all := collectDirectory(t, ReadDirectoryConfig{FS: fsys, IncludeArchives: true})
+	want := []string{"archived", "a", "b"}
+	if len(all) != len(want) {
+		t.Fatalf("got %d records, want %d", len(all), len(want))
+	}
+	for i, msg := range want {
+		if all[i].Message != msg {
+			t.Errorf("records[%d].Message = %q, want %q", i, all[i].Message, msg)
+		}
+	}
+
+	cursor := all[1].Cursor()
+	rest := collectDirectory(t, ReadDirectoryConfig{FS: fsys, IncludeArchives: true, Cursor: &cursor})
+	if len(rest) != 1 || rest[0].ID != all[2].ID {
+		t.Fatalf("resumed records = %+v, want only %q", rest, "b")
+	}
+}
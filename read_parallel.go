@@ -0,0 +1,150 @@
+package vrclog
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+)
+
+// parallelChunkSize is the nominal amount of file data framed by one
+// worker. Chunks are extended to the next newline so that every chunk
+// starts at a line boundary.
+const parallelChunkSize = 4 << 20
+
+// chunkBoundaryScanSize is the read size used when searching forward
+// for the newline that ends a chunk.
+const chunkBoundaryScanSize = 64 * 1024
+
+type chunkResult struct {
+	records []Record
+	err     error
+}
+
+// readParallel frames and hashes [start, size) of r on up to workers
+// goroutines and yields the records in offset order. Because chunks
+// begin at line starts, every record is framed exactly as the
+// sequential lineReader would frame it; line numbers are assigned in
+// order as chunks are yielded.
+func readParallel(ctx context.Context, r io.ReaderAt, start, size int64, startLine uint64, workers int, srcID SourceID, path string, opts recordOptions, yield func(Record, error) bool) {
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	order := make(chan chan chunkResult, workers)
+	sem := make(chan struct{}, workers)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(order)
+		for pos := start; pos < size; {
+			result := make(chan chunkResult, 1)
+			select {
+			case order <- result:
+			case <-ctx.Done():
+				return
+			}
+
+			end, err := nextChunkEnd(r, pos, size)
+			if err != nil {
+				result <- chunkResult{err: err}
+				return
+			}
+
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			wg.Add(1)
+			go func(chunkStart, chunkEnd int64) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				result <- frameChunk(ctx, r, chunkStart, chunkEnd, srcID, path, opts)
+			}(pos, end)
+			pos = end
+		}
+	}()
+
+	line := startLine
+	for result := range order {
+		var res chunkResult
+		select {
+		case res = <-result:
+		case <-ctx.Done():
+			return
+		}
+		// A chunk cut short by ctx reports nothing, as a sequential read
+		// that ctx stops does not.
+		if ctx.Err() != nil {
+			return
+		}
+		if res.err != nil {
+			yield(Record{}, res.err)
+			return
+		}
+		for _, rec := range res.records {
+			if ctx.Err() != nil {
+				return
+			}
+			rec.Line = line
+			line = nextLine(line)
+			if !yield(rec, nil) {
+				return
+			}
+		}
+	}
+}
+
+// nextChunkEnd returns the end of the chunk starting at pos: just past
+// the first newline at or after pos+parallelChunkSize-1, or size.
+func nextChunkEnd(r io.ReaderAt, pos, size int64) (int64, error) {
+	at := pos + parallelChunkSize - 1
+	if at >= size {
+		return size, nil
+	}
+	buf := make([]byte, chunkBoundaryScanSize)
+	for at < size {
+		n := int64(len(buf))
+		if at+n > size {
+			n = size - at
+		}
+		read, err := r.ReadAt(buf[:n], at)
+		if i := bytes.IndexByte(buf[:read], '\n'); i >= 0 {
+			return at + int64(i) + 1, nil
+		}
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+		if read == 0 {
+			break
+		}
+		at += int64(read)
+	}
+	return size, nil
+}
+
+// frameChunk frames every line of [start, end). Line numbers are left
+// zero for the caller to fill in. If ctx ends first, the result is
+// empty.
+func frameChunk(ctx context.Context, r io.ReaderAt, start, end int64, srcID SourceID, path string, opts recordOptions) chunkResult {
+	lr := newLineReader(io.NewSectionReader(r, start, end-start), start, 0, opts)
+	var records []Record
+	for {
+		if ctx.Err() != nil {
+			return chunkResult{}
+		}
+		rawBytes, rawHash, offset, nextOffset, _, _, issue, err := lr.next()
+		if err == io.EOF {
+			return chunkResult{records: records}
+		}
+		if err != nil {
+			return chunkResult{err: err}
+		}
+		records = append(records, buildRecord(rawBytes, rawHash, offset, nextOffset, 0, issue, srcID, path, opts))
+	}
+}
@@ -0,0 +1,144 @@
+package vrclog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeLargeLog writes a log spanning several parallel chunks, with an
+// oversized line, blank lines and CRLF endings scattered through it and
+// an unterminated final line.
+func writeLargeLog(t *testing.T) string {
+	t.Helper()
+	var b strings.Builder
+	for i := 0; b.Len() < 3*parallelChunkSize; i++ {
+		switch {
+		case i == 1000:
+			b.WriteString("2024.01.01 00:00:00 Log        -  ")
+			b.WriteString(strings.Repeat("x", DefaultMaxLineSize+4096))
+			b.WriteString("\n")
+		case i%97 == 0:
+			b.WriteString("\n")
+		case i%31 == 0:
+			fmt.Fprintf(&b, "2024.01.01 00:00:00 Warning    -  crlf line %d\r\n", i)
+		default:
+			fmt.Fprintf(&b, "2024.01.01 00:00:00 Log        -  line %d with some padding text\n", i)
+		}
+	}
+	b.WriteString("2024.01.01 00:00:00 Log        -  unterminated tail")
+
+	path := filepath.Join(t.TempDir(), "large.txt")
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadFile_ParallelMatchesSequential(t *testing.T) {
+	path := writeLargeLog(t)
+
+	sequential := readAll(t, ReadFileConfig{Path: path})
+	for _, workers := range []int{2, 4} {
+		parallel := readAll(t, ReadFileConfig{Path: path, Parallelism: workers})
+		assertSameRecords(t, parallel, sequential)
+		for i := range sequential {
+			if (parallel[i].Issue == nil) != (sequential[i].Issue == nil) || parallel[i].Message != sequential[i].Message {
+				t.Fatalf("workers=%d record %d: issue/message differ", workers, i)
+			}
+		}
+	}
+}
+
+func TestReadFile_ParallelFromOffset(t *testing.T) {
+	path := writeLargeLog(t)
+
+	sequential := readAll(t, ReadFileConfig{Path: path})
+	mid := sequential[len(sequential)/2].Cursor()
+
+	want := readAll(t, ReadFileConfig{Path: path, Offset: mid.Offset, Line: mid.Line})
+	got := readAll(t, ReadFileConfig{Path: path, Offset: mid.Offset, Line: mid.Line, Parallelism: 3})
+	assertSameRecords(t, got, want)
+}
+
+func TestReadFile_ParallelEarlyBreak(t *testing.T) {
+	path := writeLargeLog(t)
+
+	count := 0
+	for _, err := range ReadFile(context.Background(), ReadFileConfig{Path: path, Parallelism: 4}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		count++
+		if count == 10 {
+			break
+		}
+	}
+	if count != 10 {
+		t.Fatalf("count = %d, want 10", count)
+	}
+}
+
+// gatedReaderAt holds reads at or past from until gate is closed.
+type gatedReaderAt struct {
+	r    io.ReaderAt
+	from int64
+	gate chan struct{}
+}
+
+func (g gatedReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off >= g.from {
+		<-g.gate
+	}
+	return g.r.ReadAt(p, off)
+}
+
+func TestReadParallel_CancelYieldsNoError(t *testing.T) {
+	var b strings.Builder
+	for b.Len() < parallelChunkSize+4096 {
+		b.WriteString("2024.01.01 00:00:00 Log        -  line with some padding text\n")
+	}
+	data := strings.NewReader(b.String())
+	size := int64(b.Len())
+	second, err := nextChunkEnd(data, 0, size)
+	if err != nil || second >= size {
+		t.Fatalf("chunk end = %d, %v; want two chunks", second, err)
+	}
+
+	// A sequential read stops silently when ctx ends; so must a parallel
+	// one when ctx ends between chunks and the next chunk saw it too.
+	for range 20 {
+		ctx, cancel := context.WithCancel(context.Background())
+		r := gatedReaderAt{r: data, from: second, gate: make(chan struct{})}
+		readParallel(ctx, r, 0, size, 1, 2, "src", "", recordOptions{}, func(rec Record, err error) bool {
+			if err != nil {
+				t.Fatalf("error %v, want none", err)
+			}
+			if rec.NextOffset == second {
+				cancel()
+				close(r.gate)
+				// Let the second chunk notice the cancellation.
+				time.Sleep(time.Millisecond)
+			}
+			return true
+		})
+		cancel()
+	}
+}
+
+func TestReadFile_NegativeParallelism(t *testing.T) {
+	path := writeLog(t, t.TempDir(), "line")
+
+	var gotErr error
+	for _, err := range ReadFile(context.Background(), ReadFileConfig{Path: path, Parallelism: -1}) {
+		gotErr = err
+	}
+	if gotErr == nil {
+		t.Fatal("expected error for negative parallelism")
+	}
+}
@@ -0,0 +1,110 @@
+package vrclog
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"time"
+
+	"github.com/vrclog/vrclog-go/internal/logfile"
+)
+
+type ReaderConfig struct {
+	// SourceID identifies the log the reader's bytes come from and is
+	// required. Use SourceIDForPath to get the SourceID ReadFile and
+	// Follow use for a file, so that records match theirs.
+	SourceID SourceID
+
+	// Path is copied into Record.Path and Cursor.Path. It is only used
+	// for display and may be empty.
+	Path string
+
+	// Offset is the position in the log of the reader's first byte, for
+	// a stream that starts mid-file. Line is the line number there and is
+	// required when Offset > 0.
+	Offset int64
+	Line   uint64
+
+	// Location is the time zone header wall times are decoded in. Nil
+	// means time.Local.
+	Location *time.Location
+
+	// Header decodes line headers; see ReadFileConfig.Header.
+	Header HeaderDecoder
+
+	// Oversize selects how oversized lines are cut; see
+	// ReadFileConfig.Oversize.
+	Oversize OversizeConfig
+
+	// Encoding is the encoding of the stream. At Offset 0 a byte order
+	// mark takes precedence; a stream starting mid-file has none, so
+	// Encoding must say what the file's mark announced.
+	Encoding Encoding
+
+	// Sessions, when set, collects the SessionInfo of the stream. A
+	// stream starting mid-file has no head to collect it from.
+	Sessions *SessionExtractor
+}
+
+// ReadRecords reads log lines from r to EOF, for logs that arrive through
+// a pipe, a decompressor or an upload rather than as a file on disk.
+// Framing, RecordIDs and RecordIssues are the same as ReadFile's for the
+// same bytes and SourceID. When Offset is 0, cursors also carry the same
+// fingerprint, so a Cursor from a stream can resume a Follow of the file.
+func ReadRecords(ctx context.Context, r io.Reader, cfg ReaderConfig) iter.Seq2[Record, error] {
+	return func(yield func(Record, error) bool) {
+		if r == nil {
+			yield(Record{}, errors.New("reader is required"))
+			return
+		}
+		if cfg.SourceID == "" {
+			yield(Record{}, errors.New("source ID is required"))
+			return
+		}
+		if cfg.Offset < 0 {
+			yield(Record{}, errors.New("offset must not be negative"))
+			return
+		}
+		if cfg.Offset > 0 && cfg.Line == 0 {
+			yield(Record{}, errors.New("line is required when offset > 0"))
+			return
+		}
+		if err := cfg.Oversize.validate(); err != nil {
+			yield(Record{}, err)
+			return
+		}
+		if cfg.Encoding > EncodingUTF16BE {
+			yield(Record{}, fmt.Errorf("unknown encoding %d", cfg.Encoding))
+			return
+		}
+
+		startLine := cfg.Line
+		if startLine == 0 && cfg.Offset == 0 {
+			startLine = 1
+		}
+
+		opts := recordOptions{loc: cfg.Location, headers: cfg.Header, oversize: cfg.Oversize, encoding: cfg.Encoding, sessions: cfg.Sessions}
+		if cfg.Offset == 0 {
+			br := bufio.NewReaderSize(r, cursorHeadSize)
+			head, err := br.Peek(cursorHeadSize)
+			if err != nil && err != io.EOF {
+				yield(Record{}, err)
+				return
+			}
+			opts.head = hashSourceHead(head)
+			r = br
+		}
+
+		readSequential(ctx, r, cfg.Offset, startLine, cfg.SourceID, cfg.Path, opts, yield)
+	}
+}
+
+// SourceIDForPath returns the SourceID that ReadFile and Follow assign to
+// the log file at path. The file need not exist.
+func SourceIDForPath(path string) (SourceID, error) {
+	id, err := logfile.SourceID(path)
+	return SourceID(id), err
+}
@@ -0,0 +1,87 @@
+package vrclog
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReadRecords_MatchesReadFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "output_log_2024-01-01_00-00-00.txt")
+	content := logLine("2024.01.01 00:00:01", "first") +
+		strings.Repeat("X", DefaultMaxLineSize+100) + "\n" +
+		"2024.01.01 00:00:02 Warning    -  crlf\r\n" +
+		"  at stack trace\n" +
+		"2024.01.01 00:00:03 Log        -  \xff invalid utf8\n" +
+		strings.Repeat(logLine("2024.01.01 00:00:04", "filler"), 100) +
+		"2024.01.01 00:00:05 Log        -  unterminated"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	srcID, err := SourceIDForPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := collectSeq(t, ReadFile(context.Background(), ReadFileConfig{Path: path}))
+	// One byte per Read, so framing cannot depend on read boundaries.
+	r := iotest.OneByteReader(strings.NewReader(content))
+	got := collectSeq(t, ReadRecords(context.Background(), r, ReaderConfig{SourceID: srcID, Path: path}))
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d records, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("record %d:\n got %+v\nwant %+v", i, got[i], want[i])
+		}
+		if got[i].Cursor() != want[i].Cursor() {
+			t.Errorf("record %d cursor = %+v, want %+v", i, got[i].Cursor(), want[i].Cursor())
+		}
+	}
+	if want[1].Issue == nil || want[1].Issue.Code != RecordIssueLineTooLong {
+		t.Errorf("fixture should include a line_too_long record, got %+v", want[1].Issue)
+	}
+}
+
+func TestReadRecords_StartsMidStream(t *testing.T) {
+	line1 := logLine("2024.01.01 00:00:01", "first")
+	line2 := logLine("2024.01.01 00:00:02", "second")
+
+	full := collectSeq(t, ReadRecords(context.Background(), strings.NewReader(line1+line2), ReaderConfig{SourceID: "pipe"}))
+	tail := collectSeq(t, ReadRecords(context.Background(), strings.NewReader(line2), ReaderConfig{
+		SourceID: "pipe",
+		Offset:   int64(len(line1)),
+		Line:     2,
+	}))
+	if len(tail) != 1 || tail[0].ID != full[1].ID || tail[0].Offset != full[1].Offset || tail[0].Line != 2 {
+		t.Fatalf("tail = %+v, want the second record of the full read", tail)
+	}
+	if !tail[0].Cursor().Fingerprint.IsZero() {
+		t.Error("a mid-stream read cannot fingerprint the file head")
+	}
+}
+
+func TestReadRecords_ConfigErrors(t *testing.T) {
+	r := strings.NewReader(logLine("2024.01.01 00:00:01", "x"))
+	configs := []ReaderConfig{
+		{},
+		{SourceID: "pipe", Offset: -1},
+		{SourceID: "pipe", Offset: 10},
+	}
+	for _, cfg := range configs {
+		var gotErr error
+		for _, err := range ReadRecords(context.Background(), r, cfg) {
+			gotErr = err
+			break
+		}
+		if gotErr == nil {
+			t.Errorf("ReadRecords(%+v) should fail", cfg)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package vrclog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"iter"
+	"time"
+
+	"github.com/vrclog/vrclog-go/internal/logfile"
+)
+
+// LineUnknown is the Line of a Record whose line number was not
+// determined. Backward reads only know a line's byte range, not how
+// many lines precede it, unless ReverseReadConfig.CountLines is set.
+// Record.Cursor() of such a record keeps Line unknown, and reads and
+// follows reject it as a resume position.
+const LineUnknown uint64 = 0
+
+const reverseScanBlockSize = 64 * 1024
+
+type ReverseReadConfig struct {
+	Path string
+	// FS, when set, is the file system Path names a file in; see
+	// ReadFileConfig.FS.
+	FS fs.FS
+
+	// Limit is the number of records to return. ReadFileReverse treats
+	// 0 as "the whole file"; ReadFileTail requires it to be positive.
+	Limit int
+
+	// CountLines assigns exact Line numbers by counting the newlines in
+	// the whole file first. This is a plain byte scan without hashing,
+	// but it does read the entire file. Without it Line is LineUnknown.
+	CountLines bool
+
+	// Location is the time zone header wall times are decoded in; see
+	// ReadFileConfig.Location.
+	Location *time.Location
+
+	// Header decodes line headers; see ReadFileConfig.Header.
+	Header HeaderDecoder
+
+	// Oversize selects how oversized lines are cut; see
+	// ReadFileConfig.Oversize.
+	Oversize OversizeConfig
+}
+
+// ReadFileReverse yields the records of a plain (uncompressed) log file
+// newest-first, scanning backward from the end for line boundaries. IDs,
+// offsets, raw bytes and issues are identical to those a forward
+// ReadFile assigns; an unterminated final line is emitted first.
+func ReadFileReverse(ctx context.Context, cfg ReverseReadConfig) iter.Seq2[Record, error] {
+	return func(yield func(Record, error) bool) {
+		if cfg.Limit < 0 {
+			yield(Record{}, errors.New("limit must not be negative"))
+			return
+		}
+		rr, err := openReverseReader(cfg)
+		if err != nil {
+			yield(Record{}, err)
+			return
+		}
+		defer rr.src.Close()
+
+		for n := 0; cfg.Limit == 0 || n < cfg.Limit; n++ {
+			if ctx.Err() != nil {
+				return
+			}
+			rec, ok, err := rr.prev()
+			if err != nil {
+				yield(Record{}, err)
+				return
+			}
+			if !ok {
+				return
+			}
+			if !yield(rec, nil) {
+				return
+			}
+		}
+	}
+}
+
+// ReadFileTail yields the last cfg.Limit records of a plain log file in
+// forward order. Only the tail of the file is read, unless CountLines
+// is set.
+func ReadFileTail(ctx context.Context, cfg ReverseReadConfig) iter.Seq2[Record, error] {
+	return func(yield func(Record, error) bool) {
+		if cfg.Limit <= 0 {
+			yield(Record{}, errors.New("limit must be positive"))
+			return
+		}
+		rr, err := openReverseReader(cfg)
+		if err != nil {
+			yield(Record{}, err)
+			return
+		}
+		defer rr.src.Close()
+
+		records := make([]Record, 0, cfg.Limit)
+		for len(records) < cfg.Limit {
+			if ctx.Err() != nil {
+				return
+			}
+			rec, ok, err := rr.prev()
+			if err != nil {
+				yield(Record{}, err)
+				return
+			}
+			if !ok {
+				break
+			}
+			records = append(records, rec)
+		}
+
+		for i := len(records) - 1; i >= 0; i-- {
+			if ctx.Err() != nil {
+				return
+			}
+			if !yield(records[i], nil) {
+				return
+			}
+		}
+	}
+}
+
+// reverseReader walks a file's lines from the end.
+type reverseReader struct {
+	src  *logSource
+	path string
+	opts recordOptions
+	// end is the end offset of the next line to return.
+	end int64
+	// line is the line number of the next line to return, or
+	// LineUnknown.
+	line uint64
+
+	block      []byte
+	blockStart int64
+
+	// lr is reused for every line so that its buffer is allocated once.
+	lr *lineReader
+}
+
+func openReverseReader(cfg ReverseReadConfig) (*reverseReader, error) {
+	if cfg.Path == "" {
+		return nil, errors.New("path is required")
+	}
+	if err := cfg.Oversize.validate(); err != nil {
+		return nil, err
+	}
+	root := logfile.FSRoot(cfg.FS)
+	path, err := root.Clean(cfg.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := openLogSource(root, path, "", 0)
+	if err != nil {
+		return nil, err
+	}
+	if src.at == nil {
+		src.Close()
+		return nil, fmt.Errorf("%s: backward reads need an uncompressed file with random access", path)
+	}
+
+	head, err := readSourceHead(src.at)
+	if err != nil {
+		src.Close()
+		return nil, err
+	}
+	rr := &reverseReader{src: src, path: path, opts: recordOptions{loc: cfg.Location, headers: cfg.Header, head: head, oversize: cfg.Oversize}, end: src.size, line: LineUnknown}
+	if enc := rr.opts.textEncoding(); enc != EncodingUTF8 {
+		src.Close()
+		return nil, fmt.Errorf("%s: backward reads need a UTF-8 file, not %s", path, enc)
+	}
+	if cfg.CountLines && src.size > 0 {
+		newlines, last, err := countNewlines(src.at, src.size)
+		if err != nil {
+			src.Close()
+			return nil, err
+		}
+		rr.line = newlines
+		if last != '\n' {
+			rr.line++
+		}
+	}
+	return rr, nil
+}
+
+// prev returns the line ending at rr.end, framed by lineReader exactly
+// as a forward read frames it.
+func (rr *reverseReader) prev() (Record, bool, error) {
+	if rr.end <= 0 {
+		return Record{}, false, nil
+	}
+	// A line's own terminator sits at end-1, so the previous line's
+	// newline is the last one strictly before it.
+	start, err := rr.lastNewlineBefore(rr.end - 1)
+	if err != nil {
+		return Record{}, false, err
+	}
+
+	section := io.NewSectionReader(rr.src.at, start, rr.end-start)
+	if rr.lr == nil {
+		rr.lr = newLineReader(section, start, rr.line, rr.opts)
+	} else {
+		rr.lr.br.Reset(section)
+		rr.lr.offset = start
+		rr.lr.line = rr.line
+	}
+	rawBytes, rawHash, offset, nextOffset, lineNum, _, issue, err := rr.lr.next()
+	if err != nil {
+		return Record{}, false, err
+	}
+	rec := buildRecord(rawBytes, rawHash, offset, nextOffset, lineNum, issue, rr.src.id, rr.path, rr.opts)
+
+	rr.end = start
+	if rr.line != LineUnknown {
+		rr.line--
+	}
+	return rec, true, nil
+}
+
+// lastNewlineBefore returns the offset just past the last '\n' in
+// [0, limit), or 0 if there is none.
+func (rr *reverseReader) lastNewlineBefore(limit int64) (int64, error) {
+	for limit > 0 {
+		if limit <= rr.blockStart || limit > rr.blockStart+int64(len(rr.block)) {
+			if err := rr.loadBlockEndingAt(limit); err != nil {
+				return 0, err
+			}
+		}
+		window := rr.block[:limit-rr.blockStart]
+		if i := bytes.LastIndexByte(window, '\n'); i >= 0 {
+			return rr.blockStart + int64(i) + 1, nil
+		}
+		limit = rr.blockStart
+	}
+	return 0, nil
+}
+
+func (rr *reverseReader) loadBlockEndingAt(end int64) error {
+	start := end - reverseScanBlockSize
+	if start < 0 {
+		start = 0
+	}
+	if cap(rr.block) < int(end-start) {
+		rr.block = make([]byte, end-start)
+	}
+	rr.block = rr.block[:end-start]
+	n, err := rr.src.at.ReadAt(rr.block, start)
+	if n < len(rr.block) {
+		if err == nil || err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	rr.blockStart = start
+	return nil
+}
+
+// countNewlines counts '\n' bytes in [0, size) and returns the last byte.
+func countNewlines(r io.ReaderAt, size int64) (count uint64, last byte, err error) {
+	return countNewlinesRange(r, 0, size)
+}
+
+// countNewlinesIn counts '\n' bytes in [start, end).
+func countNewlinesIn(r io.ReaderAt, start, end int64) (uint64, error) {
+	count, _, err := countNewlinesRange(r, start, end)
+	return count, err
+}
+
+func countNewlinesRange(r io.ReaderAt, start, end int64) (count uint64, last byte, err error) {
+	buf := make([]byte, lineReaderBufSize)
+	off := start
+	for off < end {
+		n := int64(len(buf))
+		if off+n > end {
+			n = end - off
+		}
+		read, err := r.ReadAt(buf[:n], off)
+		count += uint64(bytes.Count(buf[:read], []byte{'\n'}))
+		if read > 0 {
+			last = buf[read-1]
+		}
+		if int64(read) < n {
+			if err == nil || err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, 0, err
+		}
+		off += int64(read)
+	}
+	return count, last, nil
+}
@@ -0,0 +1,126 @@
+package vrclog
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func collectSeq(t *testing.T, seq func(func(Record, error) bool)) []Record {
+	t.Helper()
+	var records []Record
+	for rec, err := range seq {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+func reversed(records []Record) []Record {
+	out := make([]Record, len(records))
+	for i, r := range records {
+		out[len(records)-1-i] = r
+	}
+	return out
+}
+
+func TestReadFileReverse_MatchesForwardRead(t *testing.T) {
+	path := writeLargeLog(t)
+	forward := readAll(t, ReadFileConfig{Path: path})
+
+	got := collectSeq(t, ReadFileReverse(context.Background(), ReverseReadConfig{Path: path, CountLines: true}))
+	assertSameRecords(t, got, reversed(forward))
+}
+
+func TestReadFileReverse_LineUnknownWithoutCounting(t *testing.T) {
+	path := writeLog(t, t.TempDir(),
+		"2024.01.01 00:00:01 Log        -  one",
+		"",
+		"2024.01.01 00:00:02 Log        -  two",
+	)
+	forward := readAll(t, ReadFileConfig{Path: path})
+
+	got := collectSeq(t, ReadFileReverse(context.Background(), ReverseReadConfig{Path: path, Limit: 2}))
+	if len(got) != 2 {
+		t.Fatalf("got %d records, want 2", len(got))
+	}
+	for i, rec := range got {
+		want := forward[len(forward)-1-i]
+		if rec.ID != want.ID || rec.Offset != want.Offset || rec.Raw != want.Raw {
+			t.Errorf("record %d = %+v, want %+v", i, rec, want)
+		}
+		if rec.Line != LineUnknown {
+			t.Errorf("record %d Line = %d, want LineUnknown", i, rec.Line)
+		}
+	}
+}
+
+func TestReadFileTail_LastRecordsInForwardOrder(t *testing.T) {
+	path := writeLargeLog(t)
+	forward := readAll(t, ReadFileConfig{Path: path})
+
+	got := collectSeq(t, ReadFileTail(context.Background(), ReverseReadConfig{Path: path, Limit: 5, CountLines: true}))
+	assertSameRecords(t, got, forward[len(forward)-5:])
+
+	all := collectSeq(t, ReadFileTail(context.Background(), ReverseReadConfig{Path: writeLog(t, t.TempDir(), "a", "b"), Limit: 10}))
+	if len(all) != 2 || all[0].Raw != "a" || all[1].Raw != "b" {
+		t.Fatalf("tail of short file = %+v", all)
+	}
+}
+
+func TestReadFileReverse_EmptyAndUnterminated(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty.txt")
+	if err := os.WriteFile(empty, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := collectSeq(t, ReadFileReverse(context.Background(), ReverseReadConfig{Path: empty})); len(got) != 0 {
+		t.Fatalf("empty file yielded %d records", len(got))
+	}
+
+	partial := filepath.Join(dir, "partial.txt")
+	if err := os.WriteFile(partial, []byte("first\nsecond"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got := collectSeq(t, ReadFileReverse(context.Background(), ReverseReadConfig{Path: partial, CountLines: true}))
+	if len(got) != 2 || got[0].Raw != "second" || got[0].Line != 2 || got[1].Raw != "first" || got[1].Line != 1 {
+		t.Fatalf("unexpected records: %+v", got)
+	}
+}
+
+func TestReadFileTail_RequiresPositiveLimit(t *testing.T) {
+	path := writeLog(t, t.TempDir(), "a")
+	var gotErr error
+	for _, err := range ReadFileTail(context.Background(), ReverseReadConfig{Path: path}) {
+		gotErr = err
+	}
+	if gotErr == nil {
+		t.Fatal("expected error for zero limit")
+	}
+}
+
+func TestReadFileReverse_CursorOfUnknownLineIsRejected(t *testing.T) {
+	dir := t.TempDir()
+	path := writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt",
+		logLine("2024.01.01 00:00:01", "one")+logLine("2024.01.01 00:00:02", "two")+logLine("2024.01.01 00:00:03", "three"))
+
+	got := collectSeq(t, ReadFileReverse(context.Background(), ReverseReadConfig{Path: path, Limit: 2}))
+	cursor := got[1].Cursor()
+	if cursor.Line != LineUnknown || cursor.Offset == 0 {
+		t.Fatalf("cursor = %+v, want a non-zero offset with LineUnknown", cursor)
+	}
+
+	for rec, err := range ReadFile(context.Background(), ReadFileConfig{Path: path, Offset: cursor.Offset, Line: cursor.Line}) {
+		if err == nil {
+			t.Fatalf("ReadFile from the cursor yielded %+v, want an error", rec)
+		}
+		break
+	}
+	_, errs := collectRecords(t, context.Background(), FollowConfig{Directory: dir, PollInterval: testPollInterval, Cursor: &cursor}, 1)
+	if len(errs) != 1 {
+		t.Errorf("Follow from the cursor: errors = %v, want one", errs)
+	}
+}
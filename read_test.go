@@ -1,13 +1,18 @@
 package vrclog
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
 	"errors"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
+	"testing/fstest"
 	"time"
 )
 
@@ -207,7 +212,7 @@ func TestReadFile_OversizedLineIsNotFatalError(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "big.txt")
 
-	bigLine := strings.Repeat("X", maxLineSize+100)
+	bigLine := strings.Repeat("X", DefaultMaxLineSize+100)
 	content := bigLine + "\n" + "2026.08.18 12:00:00 Log        -  normal line\n"
 	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
 		t.Fatal(err)
@@ -500,3 +505,122 @@ func TestReadFile_UTF8Sanitization(t *testing.T) {
 		t.Errorf("record 2 raw = %q, want %q", records[1].Raw, "ok")
 	}
 }
+
+func TestReadFile_Location(t *testing.T) {
+	ny := loadTestLocation(t, "America/New_York")
+	dir := t.TempDir()
+	path := writeLog(t, dir,
+		"2026.11.01 00:30:00 Log        -  before",
+		"2026.11.01 01:30:00 Log        -  repeated",
+		"2026.11.01 02:30:00 Log        -  after",
+	)
+
+	var records []Record
+	for rec, err := range ReadFile(context.Background(), ReadFileConfig{Path: path, Location: ny}) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		records = append(records, rec)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want 3", len(records))
+	}
+
+	want := time.Date(2026, 11, 1, 4, 30, 0, 0, time.UTC)
+	if !records[0].Time.Equal(want) {
+		t.Errorf("records[0].Time = %v, want %v", records[0].Time.UTC(), want)
+	}
+	if records[0].Issue != nil || records[2].Issue != nil {
+		t.Errorf("unexpected issues: %+v, %+v", records[0].Issue, records[2].Issue)
+	}
+	if records[1].Issue == nil || records[1].Issue.Code != RecordIssueAmbiguousTime {
+		t.Errorf("records[1].Issue = %+v, want %s", records[1].Issue, RecordIssueAmbiguousTime)
+	}
+	if records[1].Message != "repeated" {
+		t.Errorf("records[1].Message = %q, want %q", records[1].Message, "repeated")
+	}
+}
+
+func TestReadFile_FS(t *testing.T) {
+	content := logLine("2024.01.01 00:00:01", "from fs") + logLine("2024.01.01 00:00:02", "second")
+	fsys := fstest.MapFS{"logs/output_log_2024-01-01_00-00-00.txt": {Data: []byte(content)}}
+
+	var records []Record
+	for rec, err := range ReadFile(context.Background(), ReadFileConfig{FS: fsys, Path: "logs/output_log_2024-01-01_00-00-00.txt"}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, rec)
+	}
+	if len(records) != 2 || records[0].Message != "from fs" {
+		t.Fatalf("records = %+v", records)
+	}
+	if records[0].Path != "logs/output_log_2024-01-01_00-00-00.txt" {
+		t.Errorf("Path = %q, want the FS name", records[0].Path)
+	}
+	if records[1].Cursor().Fingerprint.IsZero() {
+		t.Error("records from an FS file should carry a fingerprint")
+	}
+
+	for _, err := range ReadFile(context.Background(), ReadFileConfig{FS: fsys, Path: "../escape.txt"}) {
+		if !errors.Is(err, fs.ErrInvalid) {
+			t.Errorf("invalid name error = %v, want fs.ErrInvalid", err)
+		}
+	}
+}
+
+func TestReadFile_SequentialFS(t *testing.T) {
+	first := logLine("2024.01.01 00:00:01", "a") + logLine("2024.01.01 00:00:02", "b") + logLine("2024.01.01 00:00:03", "c")
+	data := zipBytes(t, map[string]string{
+		"logs/output_log_2024-01-01_00-00-00.txt": first,
+		"logs/output_log_2024-01-02_00-00-00.txt": logLine("2024.01.02 00:00:01", "d"),
+	})
+	fsys, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := "logs/output_log_2024-01-01_00-00-00.txt"
+	f, err := fsys.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if _, ok := f.(io.ReaderAt); ok {
+		t.Fatal("zip files should only support sequential reads")
+	}
+
+	records := readAll(t, ReadFileConfig{FS: fsys, Path: path})
+	if len(records) != 3 || records[2].Message != "c" || records[2].Offset != int64(2*len(logLine("2024.01.01 00:00:01", "a"))) {
+		t.Fatalf("records = %+v", records)
+	}
+	if !records[1].Cursor().Fingerprint.IsZero() {
+		t.Error("records of a sequential file should carry no fingerprint")
+	}
+
+	// Offsets are skipped by reading, and Since and Parallelism fall back
+	// to a sequential read.
+	for name, cfg := range map[string]ReadFileConfig{
+		"offset":   {Offset: records[1].Offset, Line: records[1].Line},
+		"since":    {Since: records[1].Time},
+		"parallel": {Offset: records[1].Offset, Line: records[1].Line, Parallelism: 4},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg.FS, cfg.Path = fsys, path
+			got := readAll(t, cfg)
+			assertSameRecords(t, got, records[1:])
+		})
+	}
+
+	dirRecords := collectDirectory(t, ReadDirectoryConfig{FS: fsys, Directory: "logs"})
+	if len(dirRecords) != 4 || dirRecords[3].Message != "d" {
+		t.Errorf("directory records = %+v", dirRecords)
+	}
+
+	files, err := ListLogFiles(ListLogFilesConfig{FS: fsys, Directory: "logs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || files[0].Path != path || files[0].Size != int64(len(first)) {
+		t.Errorf("files = %+v", files)
+	}
+}
@@ -0,0 +1,91 @@
+package vrclog
+
+import (
+	"io"
+	"time"
+)
+
+// timeSeekLinearThreshold is the range size below which the Since
+// search stops bisecting and ReadFile simply reads forward.
+const timeSeekLinearThreshold = 256 * 1024
+
+// timeSeekProbeLines bounds how many lines a single probe examines
+// looking for a header before it gives up on that region.
+const timeSeekProbeLines = 256
+
+// seekSince bisects [lo, hi) of r for a line start at or before the
+// first record whose header time is at or after since. lo must be a
+// line start. VRChat header times are non-decreasing in practice; if a
+// file is not, the result is still a line start and ReadFile's filter
+// stays exact, it just may start reading earlier than necessary.
+func seekSince(r io.ReaderAt, lo, hi int64, since time.Time, opts recordOptions) (int64, error) {
+	for hi-lo > timeSeekLinearThreshold {
+		mid := lo + (hi-lo)/2
+		p, t, found, err := probeHeaderTime(r, mid, hi, opts)
+		if err != nil {
+			return 0, err
+		}
+		if found && t.Before(since) {
+			lo = p
+		} else {
+			hi = mid
+		}
+	}
+	return lo, nil
+}
+
+// probeHeaderTime finds the first line starting at or after pos and
+// returns that line start together with the time of the first headed
+// record within timeSeekProbeLines lines of it.
+func probeHeaderTime(r io.ReaderAt, pos, limit int64, opts recordOptions) (lineStart int64, t time.Time, found bool, err error) {
+	lr := newLineReader(io.NewSectionReader(r, pos-1, limit-pos+1), pos-1, 0, recordOptions{})
+	// Discard the remainder of the line containing pos-1; the next line
+	// starts right after it.
+	_, _, _, nextOffset, _, terminated, _, err := lr.next()
+	if err == io.EOF || (err == nil && !terminated) {
+		return 0, time.Time{}, false, nil
+	}
+	if err != nil {
+		return 0, time.Time{}, false, err
+	}
+	lineStart = nextOffset
+
+	for i := 0; i < timeSeekProbeLines; i++ {
+		raw, _, _, _, _, _, _, err := lr.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, time.Time{}, false, err
+		}
+		if h, ok := opts.decodeHeader(string(raw)); ok {
+			return lineStart, h.Time, true, nil
+		}
+	}
+	return lineStart, time.Time{}, false, nil
+}
+
+// timeRangeYield filters a record stream to a header time range. It
+// drops records until the first headed record at or after since
+// (header-less continuation lines of earlier records included), then
+// passes everything through until the first headed record after until,
+// where it stops the underlying read. A zero bound is open.
+func timeRangeYield(since, until time.Time, yield func(Record, error) bool) func(Record, error) bool {
+	started := since.IsZero()
+	return func(rec Record, err error) bool {
+		if err != nil {
+			return yield(rec, err)
+		}
+		headed := !rec.Time.IsZero()
+		if !started {
+			if !headed || rec.Time.Before(since) {
+				return true
+			}
+			started = true
+		}
+		if headed && !until.IsZero() && rec.Time.After(until) {
+			return false
+		}
+		return yield(rec, nil)
+	}
+}
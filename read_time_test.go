@@ -0,0 +1,140 @@
+package vrclog
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTimedLog writes one headed record per second starting at
+// 2024-01-01 00:00:00 local time, each followed by a continuation line.
+func writeTimedLog(t *testing.T, records int) string {
+	t.Helper()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)
+	var b strings.Builder
+	for i := 0; i < records; i++ {
+		ts := base.Add(time.Duration(i) * time.Second).Format(headerTimestampLayout)
+		fmt.Fprintf(&b, "%s Log        -  record %d %s\n", ts, i, strings.Repeat("p", 100))
+		fmt.Fprintf(&b, "  continuation of %d\n", i)
+	}
+	path := filepath.Join(t.TempDir(), "timed.txt")
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadFile_SinceUntilMatchesFilteredFullRead(t *testing.T) {
+	path := writeTimedLog(t, 20000)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)
+	since := base.Add(12345 * time.Second)
+	until := base.Add(12400 * time.Second)
+
+	full := readAll(t, ReadFileConfig{Path: path})
+	var want []Record
+	started := false
+	for _, rec := range full {
+		if !started && (rec.Time.IsZero() || rec.Time.Before(since)) {
+			continue
+		}
+		started = true
+		if !rec.Time.IsZero() && rec.Time.After(until) {
+			break
+		}
+		want = append(want, rec)
+	}
+
+	got := readAll(t, ReadFileConfig{Path: path, Since: since, Until: until, CountLines: true})
+	assertSameRecords(t, got, want)
+	if len(got) != 2*56 {
+		t.Fatalf("got %d records, want %d", len(got), 2*56)
+	}
+	if !strings.HasPrefix(got[0].Message, "record 12345 ") || got[len(got)-1].Raw != "  continuation of 12400" {
+		t.Errorf("range = %q .. %q", got[0].Message, got[len(got)-1].Raw)
+	}
+
+	unknown := readAll(t, ReadFileConfig{Path: path, Since: since, Until: until})
+	if len(unknown) != len(want) || unknown[0].ID != want[0].ID {
+		t.Fatalf("without CountLines got %d records", len(unknown))
+	}
+	if unknown[0].Line != LineUnknown {
+		t.Errorf("Line after jump = %d, want LineUnknown", unknown[0].Line)
+	}
+
+	parallel := readAll(t, ReadFileConfig{Path: path, Since: since, Until: until, CountLines: true, Parallelism: 4})
+	assertSameRecords(t, parallel, want)
+}
+
+func TestReadFile_UntilOnly(t *testing.T) {
+	path := writeTimedLog(t, 10)
+	until := time.Date(2024, 1, 1, 0, 0, 2, 0, time.Local)
+
+	got := readAll(t, ReadFileConfig{Path: path, Until: until})
+	if len(got) != 6 || got[0].Line != 1 {
+		t.Fatalf("got %d records starting at line %d, want 6 from line 1", len(got), got[0].Line)
+	}
+}
+
+func TestReadFile_SinceAfterEnd(t *testing.T) {
+	path := writeTimedLog(t, 5000)
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local)
+
+	if got := readAll(t, ReadFileConfig{Path: path, Since: since}); len(got) != 0 {
+		t.Fatalf("got %d records, want 0", len(got))
+	}
+}
+
+func TestReadFile_UntilBeforeSince(t *testing.T) {
+	path := writeLog(t, t.TempDir(), "a")
+	now := time.Now()
+
+	var gotErr error
+	for _, err := range ReadFile(context.Background(), ReadFileConfig{Path: path, Since: now, Until: now.Add(-time.Second)}) {
+		gotErr = err
+	}
+	if gotErr == nil {
+		t.Fatal("expected error for until before since")
+	}
+}
+
+func TestReadFile_SinceJumpCursorIsRejected(t *testing.T) {
+	path := writeTimedLog(t, 2000)
+	since := time.Date(2024, 1, 1, 0, 30, 0, 0, time.Local)
+
+	var first Record
+	for rec, err := range ReadFile(context.Background(), ReadFileConfig{Path: path, Since: since}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		first = rec
+		break
+	}
+	if first.Line != LineUnknown {
+		t.Fatalf("record after the jump has Line %d, want LineUnknown", first.Line)
+	}
+	cursor := first.Cursor()
+	if cursor.Line != LineUnknown {
+		t.Fatalf("cursor Line = %d, want LineUnknown", cursor.Line)
+	}
+	for _, err := range ReadFile(context.Background(), ReadFileConfig{Path: path, Offset: cursor.Offset, Line: cursor.Line}) {
+		if err == nil {
+			t.Fatal("resuming from the cursor should fail")
+		}
+		break
+	}
+
+	// With CountLines the cursor stays exact.
+	for rec, err := range ReadFile(context.Background(), ReadFileConfig{Path: path, Since: since, CountLines: true}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c := rec.Cursor(); c.Line != rec.Line+1 {
+			t.Errorf("cursor Line = %d, want %d", c.Line, rec.Line+1)
+		}
+		break
+	}
+}
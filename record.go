@@ -1,13 +1,53 @@
 package vrclog
 
-import (
-	"strings"
-	"time"
-)
+import "time"
 
 type RecordIssue struct {
 	Code    string `json:"code"`
 	Message string `json:"message"`
+
+	// Omitted is the byte range of the line left out of Raw, for
+	// RecordIssueLineTooLong.
+	Omitted *ByteRange `json:"omitted,omitempty"`
+
+	// Invalid lists, for RecordIssueInvalidEncoding, the byte ranges of
+	// the line that are not valid in the log's encoding, up to 16 of
+	// them. InvalidBytes counts the bytes of all ranges.
+	Invalid      []ByteRange `json:"invalid,omitempty"`
+	InvalidBytes int64       `json:"invalid_bytes,omitempty"`
+}
+
+// RecordIssue codes.
+const (
+	// RecordIssueLineTooLong marks a line longer than the oversize limit,
+	// of which Raw keeps what the OversizePolicy retains.
+	RecordIssueLineTooLong = "line_too_long"
+
+	// RecordIssueInvalidEncoding marks a line with bytes that are not
+	// valid UTF-8, or UTF-16 for a log with a UTF-16 byte order mark.
+	// Raw and Message hold U+FFFD in place of each invalid range.
+	RecordIssueInvalidEncoding = "invalid_encoding"
+
+	// RecordIssueAmbiguousTime marks a header wall time that occurs twice
+	// in the decoding location because of a DST overlap. Record.Time
+	// holds the earlier instant.
+	RecordIssueAmbiguousTime = "ambiguous_time"
+
+	// RecordIssueNonexistentTime marks a header wall time that falls in a
+	// DST gap of the decoding location. Record.Time holds the instant
+	// time.ParseInLocation normalized it to.
+	RecordIssueNonexistentTime = "nonexistent_time"
+)
+
+// affectsContent reports whether the issue means the record's message
+// may be missing part of what VRChat wrote. Time issues leave the message
+// intact, and invalid bytes only become U+FFFD in it.
+func (i *RecordIssue) affectsContent() bool {
+	switch i.Code {
+	case RecordIssueAmbiguousTime, RecordIssueNonexistentTime, RecordIssueInvalidEncoding:
+		return false
+	}
+	return true
 }
 
 type Record struct {
@@ -22,6 +62,18 @@ type Record struct {
 	NextOffset int64        `json:"next_offset"`
 	Line       uint64       `json:"line"`
 	Issue      *RecordIssue `json:"issue,omitempty"`
+
+	// Historical is set by Follow in SnapshotReplay mode on records whose
+	// bytes the start snapshot already covered.
+	Historical bool `json:"historical,omitempty"`
+
+	// Parts lists the physical lines of a logical record assembled by
+	// GroupLines. It is nil for a record of a single line.
+	Parts []RecordPart `json:"parts,omitempty"`
+
+	// head is the head of the source file when the record was read,
+	// used to fingerprint its cursor.
+	head *sourceHead
 }
 
 type Cursor struct {
@@ -29,32 +81,101 @@ type Cursor struct {
 	Path     string   `json:"path"`
 	Offset   int64    `json:"offset"`
 	Line     uint64   `json:"line"`
+
+	// Fingerprint, unless zero, is checked against the file on resume,
+	// and a mismatch fails with ErrCursorContentMismatch. Record.Cursor
+	// sets it for records read from uncompressed files.
+	Fingerprint CursorFingerprint `json:"fingerprint,omitzero"`
 }
 
 func (r Record) Cursor() Cursor {
-	return Cursor{
+	line, tailOffset, tail := r.Line, r.Offset, r.ID
+	if n := len(r.Parts); n > 0 {
+		line, tailOffset, tail = r.Parts[n-1].Line, r.Parts[n-1].Offset, r.Parts[n-1].ID
+	}
+	c := Cursor{
 		SourceID: r.SourceID,
 		Path:     r.Path,
 		Offset:   r.NextOffset,
-		Line:     r.Line + 1,
+		// A record of unknown line keeps its cursor's line unknown, so
+		// that resuming from it is rejected.
+		Line: nextLine(line),
 	}
+	if r.head != nil {
+		c.Fingerprint = CursorFingerprint{
+			HeadSize:   r.head.size,
+			Head:       r.head.hash,
+			TailOffset: tailOffset,
+			Tail:       tail,
+		}
+	}
+	return c
+}
+
+// recordOptions holds the read and follow settings that affect how a
+// framed line is turned into a Record.
+type recordOptions struct {
+	// loc is the location header wall times are decoded in; nil means
+	// time.Local.
+	loc *time.Location
+
+	// head is the head of the file being read, or nil if unknown.
+	head *sourceHead
+
+	// oversize is how the lineReader cuts oversized lines.
+	oversize OversizeConfig
+
+	// encoding is the encoding of a source without a byte order mark in
+	// head, or whose head is unknown.
+	encoding Encoding
+
+	// headers decodes line headers; nil means DefaultHeaderDecoder.
+	headers HeaderDecoder
+
+	// sessions, if not nil, collects session details from head lines.
+	sessions *SessionExtractor
 }
 
-// buildRecord constructs a Record from a lineReader.next() result.
-func buildRecord(rawBytes []byte, rawHash [32]byte, offset, nextOffset int64, lineNum uint64, issue *RecordIssue, srcID SourceID, path string) Record {
-	rawStr := strings.ToValidUTF8(string(rawBytes), "�")
+// decodeHeader decodes the header of line with the configured decoder
+// and location.
+func (o recordOptions) decodeHeader(line string) (Header, bool) {
+	loc := o.loc
+	if loc == nil {
+		loc = time.Local
+	}
+	headers := o.headers
+	if headers == nil {
+		headers = DefaultHeaderDecoder
+	}
+	return headers.DecodeHeader(line, loc)
+}
+
+// textEncoding returns the encoding the source's lines are in.
+func (o recordOptions) textEncoding() Encoding {
+	if o.head != nil && o.head.marked {
+		return o.head.encoding
+	}
+	return o.encoding
+}
 
-	t, level, message, ok := decodeHeader(rawStr, nil)
+// buildRecord constructs a Record from a lineReader.next() result. A
+// framing or encoding issue takes precedence over a header time issue.
+func buildRecord(rawBytes []byte, rawHash [32]byte, offset, nextOffset int64, lineNum uint64, issue *RecordIssue, srcID SourceID, path string, opts recordOptions) Record {
+	rawStr := string(rawBytes)
+
+	h, ok := opts.decodeHeader(rawStr)
 	if !ok {
-		message = rawStr
-		level = LevelUnknown
+		h = Header{Level: LevelUnknown, Message: rawStr}
+	}
+	if issue == nil {
+		issue = h.Issue
 	}
 
-	return Record{
+	rec := Record{
 		ID:         computeRecordID(srcID, offset, rawHash),
-		Time:       t,
-		Level:      level,
-		Message:    message,
+		Time:       h.Time,
+		Level:      h.Level,
+		Message:    h.Message,
 		Raw:        rawStr,
 		SourceID:   srcID,
 		Path:       path,
@@ -62,5 +183,8 @@ func buildRecord(rawBytes []byte, rawHash [32]byte, offset, nextOffset int64, li
 		NextOffset: nextOffset,
 		Line:       lineNum,
 		Issue:      issue,
+		head:       opts.head,
 	}
+	opts.sessions.observe(rec)
+	return rec
 }
@@ -0,0 +1,155 @@
+package vrclog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"iter"
+	"strings"
+)
+
+// DefaultGroupMaxLines is the GroupConfig.MaxLines used when it is zero.
+const DefaultGroupMaxLines = 1000
+
+// RecordPart is one physical line folded into a logical Record by
+// GroupLines.
+type RecordPart struct {
+	ID         RecordID `json:"id"`
+	Offset     int64    `json:"offset"`
+	NextOffset int64    `json:"next_offset"`
+	Line       uint64   `json:"line"`
+}
+
+type GroupConfig struct {
+	// MaxLines caps the number of physical lines in one logical record.
+	// A group that reaches it is emitted, and any further continuation
+	// lines are emitted as they are. Zero uses DefaultGroupMaxLines.
+	MaxLines int
+}
+
+// GroupLines folds header-less continuation lines, such as the stack
+// trace VRChat and Unity write after an exception, into the preceding
+// headed record of the same source. A record with continuation lines
+// becomes one logical Record:
+//
+//   - Time, Level, SourceID and Path are those of the headed line.
+//   - Message is the headed line's message followed by the raw text of
+//     each continuation line; Raw joins all raw lines. Both use "\n".
+//   - Offset and Line are those of the headed line, NextOffset that of
+//     the last line, and Parts lists every physical line in order.
+//   - ID is derived from the physical record IDs, so it is stable:
+//     logical_id = SHA-256(part_id_1 + NUL + part_id_2 + NUL + ...).
+//   - Issue is the first issue of any part.
+//
+// A headed record without continuation lines, a continuation line with
+// no headed record before it in the stream, and a blank line are passed
+// through unchanged with a nil Parts.
+//
+// A group is emitted once the next headed record or a blank line
+// arrives, the source changes, the records stop being contiguous, or
+// the stream ends. VRChat ends every entry with a blank line, so over
+// Follow a group is held only until that line is written.
+// Record.Cursor of a logical record resumes after its last part. When
+// a Checkpointer is used with Follow, set CheckpointPolicy.ManualAck
+// and acknowledge the grouped records; otherwise the checkpoint can
+// move past lines still held in a group.
+func GroupLines(records iter.Seq2[Record, error], cfg GroupConfig) iter.Seq2[Record, error] {
+	maxLines := cfg.MaxLines
+	if maxLines <= 0 {
+		maxLines = DefaultGroupMaxLines
+	}
+	return func(yield func(Record, error) bool) {
+		var group []Record
+		flush := func() bool {
+			if len(group) == 0 {
+				return true
+			}
+			rec := mergeRecordGroup(group)
+			group = group[:0]
+			return yield(rec, nil)
+		}
+
+		for rec, err := range records {
+			if err != nil {
+				if flush() {
+					yield(Record{}, err)
+				}
+				return
+			}
+
+			if len(group) > 0 && continuesGroup(group, rec, maxLines) {
+				group = append(group, rec)
+				continue
+			}
+			if !flush() {
+				return
+			}
+			if isContinuationLine(rec) {
+				if !yield(rec, nil) {
+					return
+				}
+				continue
+			}
+			group = append(group, rec)
+		}
+		flush()
+	}
+}
+
+// isContinuationLine reports whether rec has no decodable header.
+func isContinuationLine(rec Record) bool {
+	return rec.Time.IsZero() && rec.Level == LevelUnknown
+}
+
+// isBlankLine reports whether rec holds nothing but white space. VRChat
+// writes one after each entry, so it ends a group instead of joining it.
+func isBlankLine(rec Record) bool {
+	return isContinuationLine(rec) && strings.TrimSpace(rec.Raw) == ""
+}
+
+// continuesGroup reports whether rec is a non-blank continuation line
+// directly following the last line of group in the same source.
+func continuesGroup(group []Record, rec Record, maxLines int) bool {
+	last := group[len(group)-1]
+	return isContinuationLine(rec) && !isBlankLine(rec) &&
+		len(group) < maxLines &&
+		rec.SourceID == last.SourceID &&
+		rec.Offset == last.NextOffset
+}
+
+// mergeRecordGroup builds the logical record of a headed record and its
+// continuation lines.
+func mergeRecordGroup(group []Record) Record {
+	head := group[0]
+	if len(group) == 1 {
+		return head
+	}
+
+	parts := make([]RecordPart, len(group))
+	raws := make([]string, len(group))
+	for i, r := range group {
+		parts[i] = RecordPart{ID: r.ID, Offset: r.Offset, NextOffset: r.NextOffset, Line: r.Line}
+		raws[i] = r.Raw
+		if head.Issue == nil {
+			head.Issue = r.Issue
+		}
+	}
+
+	head.ID = computeGroupRecordID(parts)
+	head.Message = head.Message + "\n" + strings.Join(raws[1:], "\n")
+	head.Raw = strings.Join(raws, "\n")
+	head.NextOffset = group[len(group)-1].NextOffset
+	head.Parts = parts
+	return head
+}
+
+// logical_id = SHA-256(part_id_1 + NUL + part_id_2 + NUL + ...)
+func computeGroupRecordID(parts []RecordPart) RecordID {
+	h := sha256.New()
+	for i, p := range parts {
+		if i > 0 {
+			h.Write([]byte{0})
+		}
+		h.Write([]byte(p.ID))
+	}
+	return RecordID(hex.EncodeToString(h.Sum(nil)))
+}
@@ -0,0 +1,227 @@
+package vrclog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func groupTestLog(t *testing.T) string {
+	t.Helper()
+	return writeLog(t, t.TempDir(),
+		"2026.08.18 12:00:00 Log        -  before",
+		"2026.08.18 12:00:01 Exception  -  NullReferenceException: boom",
+		"  at Foo.Bar () [0x00000] in <abc>:0",
+		"  at Foo.Baz () [0x00000] in <abc>:0",
+		"",
+		"2026.08.18 12:00:02 Log        -  after",
+	)
+}
+
+func collectGrouped(t *testing.T, records iter.Seq2[Record, error], cfg GroupConfig) []Record {
+	t.Helper()
+	var out []Record
+	for rec, err := range GroupLines(records, cfg) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		out = append(out, rec)
+	}
+	return out
+}
+
+func TestGroupLines_FoldsContinuationLines(t *testing.T) {
+	path := groupTestLog(t)
+	ctx := context.Background()
+
+	var physical []Record
+	for rec, err := range ReadFile(ctx, ReadFileConfig{Path: path}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		physical = append(physical, rec)
+	}
+	grouped := collectGrouped(t, ReadFile(ctx, ReadFileConfig{Path: path}), GroupConfig{})
+
+	if len(grouped) != 4 {
+		t.Fatalf("got %d records, want 4", len(grouped))
+	}
+	if grouped[0].ID != physical[0].ID || grouped[0].Parts != nil {
+		t.Errorf("single-line record changed: %+v", grouped[0])
+	}
+	if grouped[2].ID != physical[4].ID || grouped[2].Parts != nil {
+		t.Errorf("blank line changed: %+v", grouped[2])
+	}
+	if grouped[3].ID != physical[5].ID || grouped[3].Parts != nil {
+		t.Errorf("single-line record changed: %+v", grouped[3])
+	}
+
+	exc := grouped[1]
+	if exc.Level != LevelException {
+		t.Errorf("Level = %q, want %q", exc.Level, LevelException)
+	}
+	wantMsg := "NullReferenceException: boom\n  at Foo.Bar () [0x00000] in <abc>:0\n  at Foo.Baz () [0x00000] in <abc>:0"
+	if exc.Message != wantMsg {
+		t.Errorf("Message = %q, want %q", exc.Message, wantMsg)
+	}
+	if exc.Offset != physical[1].Offset || exc.NextOffset != physical[3].NextOffset {
+		t.Errorf("span = [%d, %d), want [%d, %d)", exc.Offset, exc.NextOffset, physical[1].Offset, physical[3].NextOffset)
+	}
+	if exc.Line != 2 {
+		t.Errorf("Line = %d, want 2", exc.Line)
+	}
+	if len(exc.Parts) != 3 {
+		t.Fatalf("got %d parts, want 3", len(exc.Parts))
+	}
+	for i, p := range exc.Parts {
+		src := physical[1+i]
+		if p.ID != src.ID || p.Offset != src.Offset || p.NextOffset != src.NextOffset || p.Line != src.Line {
+			t.Errorf("part %d = %+v, want record %+v", i, p, src)
+		}
+	}
+
+	again := collectGrouped(t, ReadFile(ctx, ReadFileConfig{Path: path}), GroupConfig{})
+	if again[1].ID != exc.ID {
+		t.Errorf("logical ID not stable: %s vs %s", again[1].ID, exc.ID)
+	}
+	if exc.ID == physical[1].ID {
+		t.Error("logical ID equals the head record ID")
+	}
+
+	c := exc.Cursor()
+	if c.Offset != physical[4].Offset || c.Line != physical[4].Line {
+		t.Errorf("Cursor = %+v, want offset %d line %d", c, physical[4].Offset, physical[4].Line)
+	}
+}
+
+func TestGroupLines_LeadingContinuationPassesThrough(t *testing.T) {
+	path := writeLog(t, t.TempDir(),
+		"  at Foo.Bar () [0x00000] in <abc>:0",
+		"2026.08.18 12:00:00 Log        -  headed",
+	)
+	grouped := collectGrouped(t, ReadFile(context.Background(), ReadFileConfig{Path: path}), GroupConfig{})
+	if len(grouped) != 2 {
+		t.Fatalf("got %d records, want 2", len(grouped))
+	}
+	if grouped[0].Parts != nil || grouped[0].Line != 1 {
+		t.Errorf("leading continuation changed: %+v", grouped[0])
+	}
+}
+
+func TestGroupLines_BlankLinesEndGroups(t *testing.T) {
+	// An excerpt shaped like a real output_log: every entry, multi-line
+	// or not, is followed by a blank line.
+	path := writeLog(t, t.TempDir(),
+		"2024.01.01 00:00:00 Log        -  [Behaviour] OnPlayerJoined Alice (usr_00000000-0000-0000-0000-000000000001)",
+		"",
+		"2024.01.01 00:00:01 Error      -  [Always] Exception in Update",
+		"NullReferenceException: Object reference not set to an instance of an object.",
+		"  at VRC.Core.Foo.Update () [0x00000] in <00000000000000000000000000000000>:0 ",
+		"",
+		"2024.01.01 00:00:02 Warning    -  Could not load asset",
+		"",
+		"2024.01.01 00:00:03 Log        -  [Behaviour] OnPlayerLeft Alice (usr_00000000-0000-0000-0000-000000000001)",
+		"",
+	)
+	grouped := collectGrouped(t, ReadFile(context.Background(), ReadFileConfig{Path: path}), GroupConfig{})
+
+	var got []string
+	for _, rec := range grouped {
+		got = append(got, fmt.Sprintf("%d:%d", rec.Line, len(rec.Parts)))
+	}
+	want := []string{"1:0", "2:0", "3:3", "6:0", "7:0", "8:0", "9:0", "10:0"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("line:parts = %v, want %v", got, want)
+	}
+	exc := grouped[2]
+	if strings.HasSuffix(exc.Message, "\n") || !strings.HasSuffix(exc.Raw, "in <00000000000000000000000000000000>:0 ") {
+		t.Errorf("exception = %q, want it to end at the last stack line", exc.Raw)
+	}
+	if c := exc.Cursor(); c.Line != 6 {
+		t.Errorf("cursor line = %d, want 6, the blank line", c.Line)
+	}
+}
+
+func TestGroupLines_MaxLines(t *testing.T) {
+	path := groupTestLog(t)
+	grouped := collectGrouped(t, ReadFile(context.Background(), ReadFileConfig{Path: path}), GroupConfig{MaxLines: 2})
+	// before, exception+1 continuation, standalone continuation, blank, after
+	if len(grouped) != 5 {
+		t.Fatalf("got %d records, want 5", len(grouped))
+	}
+	if len(grouped[1].Parts) != 2 {
+		t.Errorf("got %d parts, want 2", len(grouped[1].Parts))
+	}
+	if grouped[2].Parts != nil || grouped[3].Parts != nil {
+		t.Error("continuations beyond MaxLines should pass through unchanged")
+	}
+}
+
+func TestGroupLines_SourceChangeEndsGroup(t *testing.T) {
+	head := Record{SourceID: "a", Offset: 0, NextOffset: 10, Line: 1, Level: LevelLog, Time: validRecord().Time}
+	other := Record{SourceID: "b", Offset: 10, NextOffset: 20, Line: 2, Level: LevelUnknown}
+	gap := Record{SourceID: "a", Offset: 30, NextOffset: 40, Line: 4, Level: LevelUnknown}
+	seq := func(yield func(Record, error) bool) {
+		for _, r := range []Record{head, other, gap} {
+			if !yield(r, nil) {
+				return
+			}
+		}
+	}
+	grouped := collectGrouped(t, seq, GroupConfig{})
+	if len(grouped) != 3 {
+		t.Fatalf("got %d records, want 3", len(grouped))
+	}
+	for i, r := range grouped {
+		if r.Parts != nil {
+			t.Errorf("record %d unexpectedly grouped", i)
+		}
+	}
+}
+
+func TestGroupLines_ErrorFlushesGroup(t *testing.T) {
+	boom := errors.New("boom")
+	head := Record{SourceID: "a", Offset: 0, NextOffset: 10, Line: 1, Level: LevelLog, Time: validRecord().Time}
+	cont := Record{SourceID: "a", Offset: 10, NextOffset: 20, Line: 2, Level: LevelUnknown, Raw: "  at Foo.Bar ()"}
+	seq := func(yield func(Record, error) bool) {
+		if yield(head, nil) && yield(cont, nil) {
+			yield(Record{}, boom)
+		}
+	}
+
+	var got []Record
+	var gotErr error
+	for rec, err := range GroupLines(seq, GroupConfig{}) {
+		if err != nil {
+			gotErr = err
+			break
+		}
+		got = append(got, rec)
+	}
+	if len(got) != 1 || len(got[0].Parts) != 2 {
+		t.Fatalf("got %+v, want one grouped record", got)
+	}
+	if !errors.Is(gotErr, boom) {
+		t.Errorf("err = %v, want %v", gotErr, boom)
+	}
+}
+
+func TestGroupLines_EngineSeesWholeException(t *testing.T) {
+	path := groupTestLog(t)
+	var seen []string
+	a := &mockAdapter{id: "group.test", decode: func(r Record) ([]Emission, error) {
+		seen = append(seen, r.Message)
+		return nil, nil
+	}}
+	eng, _ := NewEngine(a)
+	for rec := range GroupLines(ReadFile(context.Background(), ReadFileConfig{Path: path}), GroupConfig{}) {
+		eng.Process(rec)
+	}
+	if len(seen) != 4 {
+		t.Fatalf("adapter saw %d records, want 4", len(seen))
+	}
+}
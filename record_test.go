@@ -42,3 +42,21 @@ func TestRecordCursorZeroLine(t *testing.T) {
 		t.Errorf("Cursor.Offset = %d, want 50", c.Offset)
 	}
 }
+
+func TestRecordCursorGrouped(t *testing.T) {
+	r := Record{
+		SourceID:   "src-abc",
+		Path:       "/var/log/output_log.txt",
+		Offset:     100,
+		NextOffset: 300,
+		Line:       5,
+		Parts: []RecordPart{
+			{Offset: 100, NextOffset: 200, Line: 5},
+			{Offset: 200, NextOffset: 300, Line: 6},
+		},
+	}
+	c := r.Cursor()
+	if c.Offset != 300 || c.Line != 7 {
+		t.Errorf("Cursor = %+v, want offset 300 line 7", c)
+	}
+}
@@ -0,0 +1,241 @@
+package vrclog
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vrclog/vrclog-go/internal/logfile"
+)
+
+// sessionHeadLines is how many leading lines of a source are searched
+// for session details, and sessionHeadSize how many leading bytes are
+// read for them when a read starts past the head.
+const (
+	sessionHeadLines = 256
+	sessionHeadSize  = 64 << 10
+)
+
+// SessionInfo is the environment a VRChat client logs in the first lines
+// of a log. Fields it did not log are empty.
+type SessionInfo struct {
+	SourceID SourceID `json:"source_id"`
+	Path     string   `json:"path"`
+
+	// ClientBuild is the VRChat build or version string.
+	ClientBuild string `json:"client_build,omitempty"`
+	// UnityVersion is the version of the Unity engine the client runs on.
+	UnityVersion string `json:"unity_version,omitempty"`
+	// DeviceModel is the model of the machine or headset.
+	DeviceModel string `json:"device_model,omitempty"`
+	// CommandLine is the command line the client was started with.
+	CommandLine string `json:"command_line,omitempty"`
+}
+
+// sessionFields maps the message prefixes session details are logged
+// with, matched case-insensitively, to the fields they fill.
+var sessionFields = []struct {
+	prefix string
+	field  func(*SessionInfo) *string
+}{
+	{"VRChat Build: ", func(s *SessionInfo) *string { return &s.ClientBuild }},
+	{"VRChat Version: ", func(s *SessionInfo) *string { return &s.ClientBuild }},
+	{"Client Version: ", func(s *SessionInfo) *string { return &s.ClientBuild }},
+	{"Unity Version: ", func(s *SessionInfo) *string { return &s.UnityVersion }},
+	{"Initialize engine version: ", func(s *SessionInfo) *string { return &s.UnityVersion }},
+	{"Device Model: ", func(s *SessionInfo) *string { return &s.DeviceModel }},
+	{"Command Line: ", func(s *SessionInfo) *string { return &s.CommandLine }},
+	{"Command line arguments: ", func(s *SessionInfo) *string { return &s.CommandLine }},
+}
+
+// SessionExtractor collects the SessionInfo of every source read with
+// it. Set it as the Sessions field of ReadFileConfig, ReadDirectoryConfig,
+// FollowConfig, MultiFollowConfig or ReaderConfig; it is filled in as
+// the head of each source is read, and a file whose read starts past its
+// head has the head read separately, an archive by decompressing it
+// again from the start. It is safe for concurrent use, so
+// one extractor can be shared by several reads.
+type SessionExtractor struct {
+	mu       sync.Mutex
+	sessions map[SourceID]*sessionState
+	order    []SourceID
+}
+
+// sessionState is a SessionInfo being filled in. lines holds the line
+// each field was taken from, so that the earliest line wins however
+// records arrive.
+type sessionState struct {
+	info    SessionInfo
+	lines   map[*string]uint64
+	scanned bool
+}
+
+// NewSessionExtractor returns an empty SessionExtractor.
+func NewSessionExtractor() *SessionExtractor {
+	return &SessionExtractor{sessions: make(map[SourceID]*sessionState)}
+}
+
+// Session returns the SessionInfo of a source. ok is false if no line of
+// the source's head has been read yet.
+func (x *SessionExtractor) Session(id SourceID) (info SessionInfo, ok bool) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	s, ok := x.sessions[id]
+	if !ok {
+		return SessionInfo{}, false
+	}
+	return s.info, true
+}
+
+// Sessions returns the SessionInfo of every source seen, in the order
+// their first lines were read.
+func (x *SessionExtractor) Sessions() []SessionInfo {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	infos := make([]SessionInfo, len(x.order))
+	for i, id := range x.order {
+		infos[i] = x.sessions[id].info
+	}
+	return infos
+}
+
+// state returns the sessionState of a source, creating it if needed. x.mu
+// must be held.
+func (x *SessionExtractor) state(id SourceID, path string) *sessionState {
+	s, ok := x.sessions[id]
+	if !ok {
+		s = &sessionState{info: SessionInfo{SourceID: id, Path: path}, lines: make(map[*string]uint64)}
+		x.sessions[id] = s
+		x.order = append(x.order, id)
+	}
+	return s
+}
+
+// observe takes session details from rec if it is a head line. x may be
+// nil.
+func (x *SessionExtractor) observe(rec Record) {
+	if x == nil || rec.Line == LineUnknown || rec.Line > sessionHeadLines {
+		return
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	s := x.state(rec.SourceID, rec.Path)
+
+	msg := strings.TrimSpace(rec.Message)
+	if strings.HasPrefix(msg, "[Always] ") {
+		msg = strings.TrimPrefix(msg, "[Always] ")
+	}
+	for _, f := range sessionFields {
+		if len(msg) < len(f.prefix) || !strings.EqualFold(msg[:len(f.prefix)], f.prefix) {
+			continue
+		}
+		value := strings.TrimSpace(msg[len(f.prefix):])
+		if value == "" {
+			continue
+		}
+		field := f.field(&s.info)
+		if line, ok := s.lines[field]; ok && line <= rec.Line {
+			continue
+		}
+		*field, s.lines[field] = value, rec.Line
+	}
+}
+
+// scanHead reads the head of a source whose read starts past it, unless
+// it was already scanned. open returns the source from its first byte.
+// x may be nil.
+func (x *SessionExtractor) scanHead(ctx context.Context, id SourceID, path string, opts recordOptions, open func() (io.ReadCloser, error)) error {
+	if x == nil {
+		return nil
+	}
+	x.mu.Lock()
+	s := x.state(id, path)
+	scanned := s.scanned
+	s.scanned = true
+	x.mu.Unlock()
+	if scanned {
+		return nil
+	}
+
+	r, err := open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	lr := newLineReader(io.LimitReader(r, sessionHeadSize), 0, 1, opts)
+	for range sessionHeadLines {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rawBytes, rawHash, offset, nextOffset, lineNum, terminated, issue, err := lr.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !terminated {
+			return nil
+		}
+		// buildRecord observes the record.
+		buildRecord(rawBytes, rawHash, offset, nextOffset, lineNum, issue, id, path, opts)
+	}
+	return nil
+}
+
+// headAt opens the head of a source that allows random access.
+func headAt(r io.ReaderAt) func() (io.ReadCloser, error) {
+	return func() (io.ReadCloser, error) {
+		return io.NopCloser(io.NewSectionReader(r, 0, sessionHeadSize)), nil
+	}
+}
+
+// headOf opens the head of src, the log at path and entry in root. A
+// source without random access, such as an archive, is opened again
+// from its start.
+func headOf(root logfile.Root, path, entry string, src *logSource) func() (io.ReadCloser, error) {
+	if src.at != nil {
+		return headAt(src.at)
+	}
+	return func() (io.ReadCloser, error) {
+		return openLogSource(root, path, entry, 0)
+	}
+}
+
+// ReadSessionInfo reads the head of the log file cfg selects and returns
+// its SessionInfo. cfg.Sessions is replaced with an extractor of its
+// own, and Offset, Line, Since and Until are ignored.
+func ReadSessionInfo(ctx context.Context, cfg ReadFileConfig) (SessionInfo, error) {
+	x := NewSessionExtractor()
+	cfg.Sessions = x
+	cfg.Offset, cfg.Line = 0, 0
+	cfg.Since, cfg.Until = time.Time{}, time.Time{}
+	cfg.Parallelism = 0
+	for rec, err := range ReadFile(ctx, cfg) {
+		if err != nil {
+			return SessionInfo{}, err
+		}
+		if rec.Line >= sessionHeadLines {
+			break
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return SessionInfo{}, err
+	}
+	if infos := x.Sessions(); len(infos) > 0 {
+		return infos[0], nil
+	}
+	// The log is empty.
+	root := logfile.FSRoot(cfg.FS)
+	path, err := root.Clean(cfg.Path)
+	if err != nil {
+		return SessionInfo{}, err
+	}
+	id, err := root.SourceID(logfile.LogicalPath(path, cfg.Entry))
+	if err != nil {
+		return SessionInfo{}, err
+	}
+	return SessionInfo{SourceID: SourceID(id), Path: path}, nil
+}
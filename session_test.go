@@ -0,0 +1,190 @@
+package vrclog
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sessionHead is the start of a log that records session details.
+var sessionHead = logLine("2024.01.01 00:00:00", "Initialize engine version: 2022.3.22f1-DWR (887be4894c44)") +
+	logLine("2024.01.01 00:00:00", "[Always] VRChat Build: 2024.4.2p2-1543--Release") +
+	logLine("2024.01.01 00:00:00", "Environment Info:") +
+	"  Device Model: Valve Index\n" +
+	logLine("2024.01.01 00:00:01", "command line arguments: --no-vr --profile=0") +
+	logLine("2024.01.01 00:00:02", "VRChat Build: later-build")
+
+var wantSession = SessionInfo{
+	ClientBuild:  "2024.4.2p2-1543--Release",
+	UnityVersion: "2022.3.22f1-DWR (887be4894c44)",
+	DeviceModel:  "Valve Index",
+	CommandLine:  "--no-vr --profile=0",
+}
+
+func assertSession(t *testing.T, x *SessionExtractor, id SourceID, path string) {
+	t.Helper()
+	got, ok := x.Session(id)
+	if !ok {
+		t.Fatalf("no session for %s", id)
+	}
+	want := wantSession
+	want.SourceID, want.Path = id, path
+	if got != want {
+		t.Errorf("session = %+v, want %+v", got, want)
+	}
+}
+
+func TestReadFile_Sessions(t *testing.T) {
+	dir := t.TempDir()
+	path := writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt",
+		sessionHead+logLine("2024.01.01 00:00:03", "a")+logLine("2024.01.01 00:00:04", "b"))
+	id, err := SourceIDForPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	x := NewSessionExtractor()
+	records := readAll(t, ReadFileConfig{Path: path, Sessions: x})
+	assertSession(t, x, id, path)
+
+	// Reads that start past the head read it separately.
+	last := records[len(records)-1]
+	for name, cfg := range map[string]ReadFileConfig{
+		"offset":   {Path: path, Offset: last.Offset, Line: last.Line},
+		"since":    {Path: path, Since: last.Time},
+		"parallel": {Path: path, Parallelism: 4},
+	} {
+		t.Run(name, func(t *testing.T) {
+			x := NewSessionExtractor()
+			cfg.Sessions = x
+			readAll(t, cfg)
+			assertSession(t, x, id, path)
+		})
+	}
+}
+
+func TestReadFile_ArchivedSessionFromOffset(t *testing.T) {
+	dir := t.TempDir()
+	name := "output_log_2024-01-01_00-00-00.txt"
+	content := sessionHead + logLine("2024.01.01 00:00:03", "a")
+	gz := filepath.Join(dir, name+".gz")
+	if err := os.WriteFile(gz, gzipBytes(t, content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	zipped := filepath.Join(dir, "logs.zip")
+	if err := os.WriteFile(zipped, zipBytes(t, map[string]string{name: content}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	offset := int64(len(sessionHead))
+	line := uint64(strings.Count(sessionHead, "\n") + 1)
+
+	for _, path := range []string{gz, zipped} {
+		t.Run(filepath.Ext(path), func(t *testing.T) {
+			x := NewSessionExtractor()
+			records := readAll(t, ReadFileConfig{Path: path, Offset: offset, Line: line, Sessions: x})
+			if len(records) != 1 || records[0].Message != "a" {
+				t.Fatalf("records = %+v", records)
+			}
+			assertSession(t, x, records[0].SourceID, path)
+		})
+	}
+}
+
+func TestReadFile_SessionHeadOnly(t *testing.T) {
+	dir := t.TempDir()
+	var b strings.Builder
+	for range sessionHeadLines {
+		b.WriteString(logLine("2024.01.01 00:00:00", "filler"))
+	}
+	b.WriteString(logLine("2024.01.01 00:00:01", "Device Model: too late"))
+	path := writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt", b.String())
+
+	x := NewSessionExtractor()
+	readAll(t, ReadFileConfig{Path: path, Sessions: x})
+	infos := x.Sessions()
+	if len(infos) != 1 || infos[0].DeviceModel != "" {
+		t.Errorf("sessions = %+v, want one without a device model", infos)
+	}
+}
+
+func TestReadDirectory_Sessions(t *testing.T) {
+	dir := t.TempDir()
+	first := writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt", sessionHead)
+	second := writeLogFile(t, dir, "output_log_2024-01-02_00-00-00.txt", logLine("2024.01.02 00:00:00", "Unity Version: 2019.4.31f1"))
+
+	x := NewSessionExtractor()
+	collectDirectory(t, ReadDirectoryConfig{Directory: dir, Sessions: x})
+	infos := x.Sessions()
+	if len(infos) != 2 || infos[0].Path != first || infos[1].Path != second {
+		t.Fatalf("sessions = %+v, want one per file in order", infos)
+	}
+	if infos[0].ClientBuild != wantSession.ClientBuild || infos[1].UnityVersion != "2019.4.31f1" || infos[1].ClientBuild != "" {
+		t.Errorf("sessions = %+v", infos)
+	}
+}
+
+func TestFollow_Sessions(t *testing.T) {
+	dir := t.TempDir()
+	path := writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt", logLine("2024.01.01 00:00:00", "Unity Version: 2022.3.22f1-DWR"))
+	id, err := SourceIDForPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	x := NewSessionExtractor()
+	records, _ := collectRecords(t, ctx, FollowConfig{Directory: dir, PollInterval: testPollInterval, Sessions: x}, 1)
+	if info, _ := x.Session(id); info.UnityVersion != "2022.3.22f1-DWR" {
+		t.Errorf("session = %+v", info)
+	}
+
+	// A follow resumed past the head reads it separately.
+	appendToFile(t, path, logLine("2024.01.01 00:00:01", "a"))
+	cursor := records[0].Cursor()
+	x = NewSessionExtractor()
+	collectRecords(t, ctx, FollowConfig{Directory: dir, PollInterval: testPollInterval, Cursor: &cursor, Sessions: x}, 1)
+	if info, _ := x.Session(id); info.UnityVersion != "2022.3.22f1-DWR" {
+		t.Errorf("resumed session = %+v", info)
+	}
+}
+
+func TestReadRecords_Sessions(t *testing.T) {
+	x := NewSessionExtractor()
+	collectSeq(t, ReadRecords(context.Background(), strings.NewReader(sessionHead), ReaderConfig{SourceID: "stream", Sessions: x}))
+	assertSession(t, x, "stream", "")
+}
+
+func TestReadSessionInfo(t *testing.T) {
+	dir := t.TempDir()
+	path := writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt", sessionHead)
+	id, err := SourceIDForPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := ReadSessionInfo(context.Background(), ReadFileConfig{Path: path, Offset: 10, Line: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := wantSession
+	want.SourceID, want.Path = id, path
+	if info != want {
+		t.Errorf("ReadSessionInfo = %+v, want %+v", info, want)
+	}
+
+	empty := filepath.Join(dir, "output_log_2024-01-02_00-00-00.txt")
+	if err := os.WriteFile(empty, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	info, err = ReadSessionInfo(context.Background(), ReadFileConfig{Path: empty})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Path != empty || info.SourceID == "" || info.ClientBuild != "" {
+		t.Errorf("empty log session = %+v", info)
+	}
+}
@@ -1,10 +1,12 @@
 package vrclog
 
 import (
+	"cmp"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	"io/fs"
+	"slices"
 
 	"github.com/vrclog/vrclog-go/internal/logfile"
 )
@@ -23,9 +25,34 @@ import (
 // will report true for any record within the captured size. VRChat log
 // files are created with unique timestamped names
 // (output_log_YYYY-MM-DD_HH-MM-SS.txt), so same-path replacement does
-// not occur in practice.
+// not occur in practice. Where the OS provides file identity, Diff does
+// detect such a replacement.
+//
+// A LogSnapshot can be persisted with encoding/json and reused after a
+// restart.
 type LogSnapshot struct {
-	sizes map[SourceID]int64
+	files map[SourceID]SnapshotFile
+}
+
+// SnapshotFile is one log file as captured in a LogSnapshot.
+type SnapshotFile struct {
+	SourceID SourceID `json:"source_id"`
+	Path     string   `json:"path"`
+	Size     int64    `json:"size"`
+
+	// Identity is the file's device and inode number, or on Windows its
+	// volume serial number and file index. It is nil where the OS does
+	// not provide them.
+	Identity *FileIdentity `json:"identity,omitempty"`
+}
+
+// FileIdentity identifies a file independently of its path.
+type FileIdentity struct {
+	// Device is the device number, or the volume serial number on
+	// Windows.
+	Device uint64 `json:"device"`
+	// Inode is the inode number, or the file index on Windows.
+	Inode uint64 `json:"inode"`
 }
 
 // CaptureLogSnapshot captures the byte head of every currently existing
@@ -41,34 +68,52 @@ func CaptureLogSnapshot(directory string) (LogSnapshot, error) {
 		}
 		dir = d
 	}
-	absDir, err := filepath.Abs(dir)
+	return captureLogSnapshot(logfile.Root{}, dir)
+}
+
+// CaptureLogSnapshotFS is CaptureLogSnapshot for a directory of fsys,
+// named as in io/fs. Paths and SourceIDs are those ReadFile and Follow
+// use with the same FS.
+func CaptureLogSnapshotFS(fsys fs.FS, directory string) (LogSnapshot, error) {
+	if fsys == nil {
+		return LogSnapshot{}, errors.New("fs is required")
+	}
+	return captureLogSnapshot(logfile.FSRoot(fsys), directory)
+}
+
+func captureLogSnapshot(root logfile.Root, directory string) (LogSnapshot, error) {
+	dir, err := root.Clean(directory)
 	if err != nil {
 		return LogSnapshot{}, err
 	}
-	dir = filepath.Clean(absDir)
 
-	files, err := logfile.ListLogFilesStrict(dir)
+	files, err := root.ListLogFilesStrict(dir)
 	if err != nil {
 		if errors.Is(err, logfile.ErrNoLogFiles) {
-			return LogSnapshot{sizes: map[SourceID]int64{}}, nil
+			return LogSnapshot{files: map[SourceID]SnapshotFile{}}, nil
 		}
 		return LogSnapshot{}, err
 	}
 
-	sizes := make(map[SourceID]int64, len(files))
+	snapFiles := make(map[SourceID]SnapshotFile, len(files))
 	for _, f := range files {
-		info, err := os.Stat(f.Path)
+		info, err := root.Stat(f.Path)
 		if err != nil {
 			return LogSnapshot{}, fmt.Errorf("stat %s: %w", f.Path, err)
 		}
-		srcIDStr, err := logfile.SourceID(f.Path)
+		srcIDStr, err := root.SourceID(f.Path)
 		if err != nil {
 			return LogSnapshot{}, fmt.Errorf("source ID for %s: %w", f.Path, err)
 		}
-		sizes[SourceID(srcIDStr)] = info.Size()
+		snapFiles[SourceID(srcIDStr)] = SnapshotFile{
+			SourceID: SourceID(srcIDStr),
+			Path:     f.Path,
+			Size:     info.Size(),
+			Identity: fileIdentity(root, f.Path, info),
+		}
 	}
 
-	return LogSnapshot{sizes: sizes}, nil
+	return LogSnapshot{files: snapFiles}, nil
 }
 
 // Contains reports whether all bytes of record already existed when the
@@ -76,9 +121,109 @@ func CaptureLogSnapshot(directory string) (LogSnapshot, error) {
 // snapshot, and record.NextOffset must not exceed the captured size for
 // that source.
 func (s LogSnapshot) Contains(record Record) bool {
-	size, ok := s.sizes[record.SourceID]
+	f, ok := s.files[record.SourceID]
 	if !ok {
 		return false
 	}
-	return record.NextOffset <= size
+	return record.NextOffset <= f.Size
+}
+
+// Files returns the captured files ordered by path.
+func (s LogSnapshot) Files() []SnapshotFile {
+	files := make([]SnapshotFile, 0, len(s.files))
+	for _, f := range s.files {
+		files = append(files, f)
+	}
+	slices.SortFunc(files, func(a, b SnapshotFile) int { return cmp.Compare(a.Path, b.Path) })
+	return files
+}
+
+// snapshotVersion is the version of the LogSnapshot JSON form.
+const snapshotVersion = 1
+
+type snapshotJSON struct {
+	Version int            `json:"version"`
+	Files   []SnapshotFile `json:"files"`
+}
+
+// MarshalJSON encodes s as a versioned object listing its files.
+func (s LogSnapshot) MarshalJSON() ([]byte, error) {
+	return json.Marshal(snapshotJSON{Version: snapshotVersion, Files: s.Files()})
+}
+
+// UnmarshalJSON decodes a snapshot encoded by MarshalJSON.
+func (s *LogSnapshot) UnmarshalJSON(data []byte) error {
+	var v snapshotJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.Version != snapshotVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidSnapshot, v.Version)
+	}
+	files := make(map[SourceID]SnapshotFile, len(v.Files))
+	for _, f := range v.Files {
+		if f.SourceID == "" || f.Size < 0 {
+			return fmt.Errorf("%w: bad entry for %q", ErrInvalidSnapshot, f.Path)
+		}
+		if _, dup := files[f.SourceID]; dup {
+			return fmt.Errorf("%w: duplicate source %s", ErrInvalidSnapshot, f.SourceID)
+		}
+		files[f.SourceID] = f
+	}
+	s.files = files
+	return nil
+}
+
+// SnapshotDiff describes how a later LogSnapshot differs from an earlier
+// one. Each list is ordered by path.
+type SnapshotDiff struct {
+	// New lists files only the later snapshot has.
+	New []SnapshotFile `json:"new,omitempty"`
+
+	// Grown lists files whose bytes in Range were appended in between.
+	Grown []SnapshotChange `json:"grown,omitempty"`
+
+	// Shrunk lists files whose bytes in Range no longer exist.
+	Shrunk []SnapshotChange `json:"shrunk,omitempty"`
+
+	// Replaced lists files whose identity changed at the same path, so
+	// none of the earlier bytes can be assumed to remain. Only reported
+	// when both snapshots carry an identity for the file.
+	Replaced []SnapshotFile `json:"replaced,omitempty"`
+
+	// Vanished lists files only the earlier snapshot has.
+	Vanished []SnapshotFile `json:"vanished,omitempty"`
+}
+
+// SnapshotChange is a file whose size differs between two snapshots.
+// SnapshotFile is the file as the later snapshot captured it.
+type SnapshotChange struct {
+	SnapshotFile
+	PreviousSize int64     `json:"previous_size"`
+	Range        ByteRange `json:"range"`
+}
+
+// Diff reports how later differs from s. Files whose size and identity
+// are unchanged are not listed.
+func (s LogSnapshot) Diff(later LogSnapshot) SnapshotDiff {
+	var d SnapshotDiff
+	for _, f := range later.Files() {
+		prev, ok := s.files[f.SourceID]
+		switch {
+		case !ok:
+			d.New = append(d.New, f)
+		case prev.Identity != nil && f.Identity != nil && *prev.Identity != *f.Identity:
+			d.Replaced = append(d.Replaced, f)
+		case f.Size > prev.Size:
+			d.Grown = append(d.Grown, SnapshotChange{SnapshotFile: f, PreviousSize: prev.Size, Range: ByteRange{Start: prev.Size, End: f.Size}})
+		case f.Size < prev.Size:
+			d.Shrunk = append(d.Shrunk, SnapshotChange{SnapshotFile: f, PreviousSize: prev.Size, Range: ByteRange{Start: f.Size, End: prev.Size}})
+		}
+	}
+	for _, f := range s.Files() {
+		if _, ok := later.files[f.SourceID]; !ok {
+			d.Vanished = append(d.Vanished, f)
+		}
+	}
+	return d
 }
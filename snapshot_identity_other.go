@@ -0,0 +1,14 @@
+//go:build !unix && !windows
+
+package vrclog
+
+import (
+	"os"
+
+	"github.com/vrclog/vrclog-go/internal/logfile"
+)
+
+// fileIdentity returns nil: os.FileInfo carries no file index here.
+func fileIdentity(logfile.Root, string, os.FileInfo) *FileIdentity {
+	return nil
+}
@@ -0,0 +1,19 @@
+//go:build unix
+
+package vrclog
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/vrclog/vrclog-go/internal/logfile"
+)
+
+// fileIdentity returns the device and inode number of info.
+func fileIdentity(_ logfile.Root, _ string, info os.FileInfo) *FileIdentity {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	return &FileIdentity{Device: uint64(st.Dev), Inode: uint64(st.Ino)}
+}
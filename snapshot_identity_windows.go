@@ -0,0 +1,36 @@
+//go:build windows
+
+package vrclog
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/vrclog/vrclog-go/internal/logfile"
+)
+
+// fileIdentity returns the volume serial number and file index of the
+// host file at path. os.FileInfo does not carry them on Windows, so the
+// file is opened, without an access right or a share restriction, to
+// ask for them. Files of an fs.FS have no identity.
+func fileIdentity(root logfile.Root, path string, _ os.FileInfo) *FileIdentity {
+	if !root.IsHost() {
+		return nil
+	}
+	p, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return nil
+	}
+	h, err := syscall.CreateFile(p, 0,
+		syscall.FILE_SHARE_READ|syscall.FILE_SHARE_WRITE|syscall.FILE_SHARE_DELETE,
+		nil, syscall.OPEN_EXISTING, syscall.FILE_FLAG_BACKUP_SEMANTICS, 0)
+	if err != nil {
+		return nil
+	}
+	defer syscall.CloseHandle(h)
+	var d syscall.ByHandleFileInformation
+	if err := syscall.GetFileInformationByHandle(h, &d); err != nil {
+		return nil
+	}
+	return &FileIdentity{Device: uint64(d.VolumeSerialNumber), Inode: uint64(d.FileIndexHigh)<<32 | uint64(d.FileIndexLow)}
+}
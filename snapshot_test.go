@@ -2,10 +2,14 @@ package vrclog
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"testing"
+	"testing/fstest"
 )
 
 func TestCaptureLogSnapshot_EmptyDirectory(t *testing.T) {
@@ -15,8 +19,8 @@ func TestCaptureLogSnapshot_EmptyDirectory(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if len(snap.sizes) != 0 {
-		t.Errorf("expected empty snapshot, got %d entries", len(snap.sizes))
+	if len(snap.files) != 0 {
+		t.Errorf("expected empty snapshot, got %d entries", len(snap.files))
 	}
 }
 
@@ -228,8 +232,8 @@ func TestLogSnapshot_ReusesDirectoryNormalization(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if len(snap.sizes) != 1 {
-		t.Fatalf("expected 1 source in snapshot, got %d", len(snap.sizes))
+	if len(snap.files) != 1 {
+		t.Fatalf("expected 1 source in snapshot, got %d", len(snap.files))
 	}
 }
 
@@ -255,3 +259,175 @@ func TestLogSnapshot_StrictDiscoveryPropagatesErrors(t *testing.T) {
 		t.Fatal("expected error for unreadable candidate file")
 	}
 }
+
+func TestLogSnapshot_JSONRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	content := logLine("2024.01.01 00:00:01", "line one")
+	path := writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt", content)
+	writeLogFile(t, dir, "output_log_2024-01-02_00-00-00.txt", "")
+
+	snap, err := CaptureLogSnapshot(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got LogSnapshot
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("unmarshal %s: %v", data, err)
+	}
+	if !reflect.DeepEqual(got.Files(), snap.Files()) {
+		t.Errorf("round trip files = %+v, want %+v", got.Files(), snap.Files())
+	}
+	if files := got.Files(); len(files) != 2 || files[0].Path != path || files[0].Size != int64(len(content)) {
+		t.Errorf("files = %+v", files)
+	}
+	if got.Files()[0].Identity == nil {
+		t.Error("expected file identity on this OS")
+	}
+
+	appendToFile(t, path, logLine("2024.01.01 00:00:02", "line two"))
+	var records []Record
+	for rec, err := range ReadFile(context.Background(), ReadFileConfig{Path: path}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, rec)
+	}
+	if !got.Contains(records[0]) || got.Contains(records[1]) {
+		t.Error("decoded snapshot should contain only the pre-capture record")
+	}
+}
+
+func TestLogSnapshot_UnmarshalInvalid(t *testing.T) {
+	inputs := []string{
+		`{"version":2,"files":[]}`,
+		`{"version":1,"files":[{"source_id":"","path":"a","size":1}]}`,
+		`{"version":1,"files":[{"source_id":"x","path":"a","size":-1}]}`,
+		`{"version":1,"files":[{"source_id":"x","path":"a","size":1},{"source_id":"x","path":"b","size":2}]}`,
+	}
+	for _, in := range inputs {
+		var snap LogSnapshot
+		if err := json.Unmarshal([]byte(in), &snap); !errors.Is(err, ErrInvalidSnapshot) {
+			t.Errorf("Unmarshal(%s) error = %v, want ErrInvalidSnapshot", in, err)
+		}
+	}
+}
+
+func TestLogSnapshot_Diff(t *testing.T) {
+	dir := t.TempDir()
+	growing := writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt", logLine("2024.01.01 00:00:01", "a"))
+	shrinking := writeLogFile(t, dir, "output_log_2024-01-02_00-00-00.txt", logLine("2024.01.02 00:00:01", "b"))
+	vanishing := writeLogFile(t, dir, "output_log_2024-01-03_00-00-00.txt", logLine("2024.01.03 00:00:01", "c"))
+	unchanged := writeLogFile(t, dir, "output_log_2024-01-04_00-00-00.txt", logLine("2024.01.04 00:00:01", "d"))
+
+	before, err := CaptureLogSnapshot(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	appendToFile(t, growing, logLine("2024.01.01 00:00:02", "more"))
+	if err := os.Truncate(shrinking, 5); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(vanishing); err != nil {
+		t.Fatal(err)
+	}
+	added := writeLogFile(t, dir, "output_log_2024-01-05_00-00-00.txt", logLine("2024.01.05 00:00:01", "e"))
+
+	after, err := CaptureLogSnapshot(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := before.Diff(after)
+
+	if len(d.New) != 1 || d.New[0].Path != added {
+		t.Errorf("New = %+v, want %s", d.New, added)
+	}
+	oldSize := int64(len(logLine("2024.01.01 00:00:01", "a")))
+	newSize := oldSize + int64(len(logLine("2024.01.01 00:00:02", "more")))
+	if len(d.Grown) != 1 || d.Grown[0].Path != growing || d.Grown[0].Range != (ByteRange{Start: oldSize, End: newSize}) {
+		t.Errorf("Grown = %+v, want %s [%d, %d)", d.Grown, growing, oldSize, newSize)
+	}
+	if len(d.Shrunk) != 1 || d.Shrunk[0].Path != shrinking || d.Shrunk[0].Range.Start != 5 ||
+		d.Shrunk[0].PreviousSize != d.Shrunk[0].Range.End {
+		t.Errorf("Shrunk = %+v, want %s from 5", d.Shrunk, shrinking)
+	}
+	if len(d.Vanished) != 1 || d.Vanished[0].Path != vanishing {
+		t.Errorf("Vanished = %+v, want %s", d.Vanished, vanishing)
+	}
+	if len(d.Replaced) != 0 {
+		t.Errorf("Replaced = %+v, want none", d.Replaced)
+	}
+	for _, f := range append(append(d.New, d.Vanished...), d.Replaced...) {
+		if f.Path == unchanged {
+			t.Errorf("unchanged file %s reported", unchanged)
+		}
+	}
+
+	if d := after.Diff(after); !reflect.DeepEqual(d, SnapshotDiff{}) {
+		t.Errorf("self diff = %+v, want empty", d)
+	}
+}
+
+func TestLogSnapshot_DiffReplaced(t *testing.T) {
+	dir := t.TempDir()
+	path := writeLogFile(t, dir, "output_log_2024-01-01_00-00-00.txt", logLine("2024.01.01 00:00:01", "a"))
+
+	before, err := CaptureLogSnapshot(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmp := filepath.Join(dir, "replacement.tmp")
+	if err := os.WriteFile(tmp, []byte(logLine("2024.01.01 00:00:01", "a")+logLine("2024.01.01 00:00:02", "b")), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+
+	after, err := CaptureLogSnapshot(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := before.Diff(after)
+	if len(d.Replaced) != 1 || d.Replaced[0].Path != path || len(d.Grown) != 0 {
+		t.Errorf("diff = %+v, want %s replaced", d, path)
+	}
+}
+
+func TestCaptureLogSnapshotFS(t *testing.T) {
+	content := logLine("2024.01.01 00:00:01", "line one")
+	fsys := fstest.MapFS{"logs/output_log_2024-01-01_00-00-00.txt": {Data: []byte(content)}}
+
+	snap, err := CaptureLogSnapshotFS(fsys, "logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := snap.Files()
+	if len(files) != 1 || files[0].Path != "logs/output_log_2024-01-01_00-00-00.txt" || files[0].Size != int64(len(content)) {
+		t.Fatalf("files = %+v", files)
+	}
+	if files[0].Identity != nil {
+		t.Errorf("MapFS files have no identity, got %+v", files[0].Identity)
+	}
+
+	fsys["logs/output_log_2024-01-01_00-00-00.txt"].Data = []byte(content + logLine("2024.01.01 00:00:02", "line two"))
+	var records []Record
+	for rec, err := range ReadFile(context.Background(), ReadFileConfig{FS: fsys, Path: files[0].Path}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, rec)
+	}
+	if len(records) != 2 || !snap.Contains(records[0]) || snap.Contains(records[1]) {
+		t.Errorf("snapshot should contain exactly the first of %d records", len(records))
+	}
+
+	if _, err := CaptureLogSnapshotFS(nil, "logs"); err == nil {
+		t.Error("nil FS should be rejected")
+	}
+}